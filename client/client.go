@@ -0,0 +1,144 @@
+// Package client talks to a ppstat-server: it uploads the evaluation keys and the encrypted
+// columns of an engine.Client and requests the statistics, which are returned encrypted.
+// Nothing sent by this package allows the server to decrypt.
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/hm-choi/pp-stat/engine"
+	"github.com/hm-choi/pp-stat/server"
+)
+
+// Operations supported by the server.
+const (
+	OpMean     = "mean"
+	OpVariance = "variance"
+	OpZScore   = "zscore"
+	OpSkewness = "skewness"
+	OpKurtosis = "kurtosis"
+	OpCoeffVar = "cv"
+	OpPCC      = "pcc"
+)
+
+// Client is a session with a ppstat-server.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	session string
+}
+
+// New returns a Client for the server at baseURL, e.g. "http://127.0.0.1:8080".
+// If httpClient is nil, http.DefaultClient is used.
+func New(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{baseURL: strings.TrimSuffix(baseURL, "/"), http: httpClient}
+}
+
+// Open uploads the parameters and the evaluation keys of c and starts a session.
+func (cl *Client) Open(c *engine.Client, evk *engine.EvaluationKeys) error {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(c.WriteEvaluationKeys(pw, evk))
+	}()
+
+	var resp struct {
+		Session string `json:"session"`
+	}
+	if err := cl.do(http.MethodPost, "/v1/sessions", "application/octet-stream", pr, &resp); err != nil {
+		return fmt.Errorf("open session: %w", err)
+	}
+	cl.session = resp.Session
+	return nil
+}
+
+// Upload sends an encrypted column and returns the identifier to use in Compute.
+func (cl *Client) Upload(d *engine.HEData) (string, error) {
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		return "", fmt.Errorf("encode data: %w", err)
+	}
+
+	var resp struct {
+		Data string `json:"data"`
+	}
+	if err := cl.do(http.MethodPost, cl.sessionPath("/data"), "application/octet-stream", &buf, &resp); err != nil {
+		return "", fmt.Errorf("upload data: %w", err)
+	}
+	return resp.Data, nil
+}
+
+// Compute evaluates op on the uploaded columns and returns the encrypted result.
+// B is the constant scaling factor of the algorithm, it is ignored by mean and variance.
+// The moment statistics use the default engine.MomentOptions, see ComputeWithOptions.
+func (cl *Client) Compute(op string, B float64, inputs ...string) (*engine.HEData, error) {
+	return cl.ComputeWithOptions(op, B, engine.MomentOptions{}, inputs...)
+}
+
+// ComputeWithOptions is Compute with the estimator and the kurtosis of opts for the variance,
+// skewness, kurtosis and coefficient of variation.
+func (cl *Client) ComputeWithOptions(op string, B float64, opts engine.MomentOptions, inputs ...string) (*engine.HEData, error) {
+	req := server.ComputeRequest{Op: op, Inputs: inputs, B: B, Estimator: opts.Estimator.String(), Raw: opts.Raw}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(engine.HEData)
+	if err = cl.do(http.MethodPost, cl.sessionPath("/compute"), "application/json", bytes.NewReader(body), result); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return result, nil
+}
+
+// Close ends the session and lets the server release the keys and the data.
+func (cl *Client) Close() error {
+	if err := cl.do(http.MethodDelete, cl.sessionPath(""), "", nil, nil); err != nil {
+		return fmt.Errorf("close session: %w", err)
+	}
+	return nil
+}
+
+func (cl *Client) sessionPath(suffix string) string {
+	return "/v1/sessions/" + cl.session + suffix
+}
+
+// do sends a request and decodes the response into out, which is either an
+// io.ReaderFrom for binary responses or a value decoded as JSON.
+func (cl *Client) do(method, path, contentType string, body io.Reader, out any) error {
+	req, err := http.NewRequest(method, cl.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := cl.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	switch out := out.(type) {
+	case nil:
+		return nil
+	case io.ReaderFrom:
+		_, err = out.ReadFrom(resp.Body)
+	default:
+		err = json.NewDecoder(resp.Body).Decode(out)
+	}
+	return err
+}
@@ -0,0 +1,26 @@
+// Command ppstat-server serves the pp-stat statistics over HTTP.
+// Clients upload their evaluation keys and encrypted columns with the client package
+// and get the encrypted results back; the server never holds a secret key.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/hm-choi/pp-stat/server"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8080", "address to listen on")
+	maxKeyBytes := flag.Int64("max-key-bytes", server.DefaultMaxKeyBytes, "largest accepted evaluation keys, in bytes")
+	maxDataBytes := flag.Int64("max-data-bytes", server.DefaultMaxDataBytes, "largest accepted encrypted column, in bytes")
+	flag.Parse()
+
+	h := server.NewHandler()
+	h.MaxKeyBytes = *maxKeyBytes
+	h.MaxDataBytes = *maxDataBytes
+
+	log.Printf("ppstat-server listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, h))
+}
@@ -4,19 +4,142 @@ import (
 	"fmt"
 
 	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
 	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
 	"github.com/tuneinsight/lattigo/v6/utils"
 )
 
+// Parameters is a named parameter set of pp-stat. Build returns the CKKS and bootstrapping parameters it describes.
 type Parameters struct {
+	Name  string
 	LogN  int
-	Level int
-	Scale float64
+	Level int // usable depth: a bootstrapped ciphertext is refreshed to Level
+	Scale int
+	IsBTS bool
+
+	// Insecure allows the generation of keys with parameters estimated below 128-bit security.
+	// It is never set by a preset and must be set explicitly by the caller.
+	Insecure bool
+
+	Security   int     // estimated security in bits, see Security; 0 if below 128 bits
+	Throughput float64 // measured number of values per second of Skewness on a single core; 0 if not measured
+}
+
+var (
+	// Test has small keys and fast operations for tests and examples. It is not secure.
+	// Its throughput was measured by BenchmarkSkewness of the engine package on one core of an Intel Xeon.
+	Test = Parameters{Name: "Test", LogN: 10, Level: 11, Scale: 40, IsBTS: true, Security: 0, Throughput: 5000}
+
+	// Sec128Small is the parameter set of the experiments in examples/README.md.
+	// Its throughput has not been measured yet.
+	Sec128Small = Parameters{Name: "Sec128Small", LogN: 16, Level: 11, Scale: 40, IsBTS: true, Security: 128}
+
+	// Sec128Large has the slots of Sec128Small with a larger depth at 128-bit security, so fewer bootstraps are needed.
+	// Its throughput has not been measured yet.
+	Sec128Large = Parameters{Name: "Sec128Large", LogN: 16, Level: 16, Scale: 40, IsBTS: true, Security: 128}
+)
+
+// Presets lists the named parameter sets.
+var Presets = []Parameters{Test, Sec128Small, Sec128Large}
+
+// Slots returns the number of values packed in a ciphertext.
+func (p Parameters) Slots() int { return 1 << (p.LogN - 1) }
+
+// Depth returns the number of multiplications between two bootstraps.
+func (p Parameters) Depth() int { return p.Level }
+
+// Build returns the parameters described by p with NewParameters.
+func (p Parameters) Build() (bool, ckks.Parameters, bootstrapping.Parameters, error) {
+	return NewParameters(p.LogN, p.Level, p.Scale, p.IsBTS)
+}
+
+func (p Parameters) String() string {
+	return fmt.Sprintf("%s (LogN=%d, Level=%d, Scale=%d, %d-bit security)", p.Name, p.LogN, p.Level, p.Scale, p.Security)
+}
+
+// maxLogQP holds, for LogN from 10 to 15, the largest log2(QP) with 128, 192 and 256 bits of security
+// for a uniform ternary secret, from the Homomorphic Encryption Standard.
+var maxLogQP = [][3]float64{
+	{27, 19, 14},
+	{54, 37, 29},
+	{109, 75, 58},
+	{218, 152, 118},
+	{438, 305, 237},
+	{881, 611, 476},
+}
+
+// Security returns the estimated security in bits, 128, 192 or 256, of a ring of degree 2^LogN with a
+// modulus of LogQP bits and a uniform ternary secret, as generated by NewParameters. It returns 0 below 128 bits.
+// Above LogN=15 the bounds are doubled with each LogN, as they grow linearly with the ring degree.
+func Security(LogN int, LogQP float64) int {
+	if LogN < 10 {
+		return 0
+	}
+	idx := min(LogN, 15) - 10
+	factor := float64(int(1) << (LogN - 10 - idx))
+	for i := len(maxLogQP[idx]) - 1; i >= 0; i-- {
+		if LogQP <= maxLogQP[idx][i]*factor {
+			return 128 + 64*i
+		}
+	}
+	return 0
+}
+
+// CheckSecurity returns an error if the keys of params, or of btpParams if isBTS is set, are estimated below 128-bit security.
+func CheckSecurity(isBTS bool, params ckks.Parameters, btpParams bootstrapping.Parameters) error {
+	logQP := params.LogQP()
+	if isBTS {
+		// The bootstrapping keys are generated over the larger modulus of the bootstrapping circuit.
+		logQP = btpParams.BootstrappingParameters.LogQP()
+	}
+	if Security(params.LogN(), logQP) < 128 {
+		return fmt.Errorf("insecure parameters: LogN=%d with a modulus of %.0f bits is estimated below 128-bit security; set Insecure to use them anyway",
+			params.LogN(), logQP)
+	}
+	return nil
+}
+
+// FirstModulusSize is the bit size of the first prime of the ciphertext modulus, which holds
+// the integer part of the values at level 0.
+const FirstModulusSize = 60
+
+// MinScale is the smallest log2 scale: below it the CKKS error leaves too few bits of precision.
+const MinScale = 20
+
+// MinBootstrapLevel is the smallest Level with which every statistic can be evaluated when
+// bootstrapping is enabled. A bootstrapped ciphertext is refreshed to Level, and the Chebyshev
+// approximation of the inverse square root (depth 9) with its input scaling and the final
+// multiplication of the statistics consume 11 levels without bootstrapping in between.
+const MinBootstrapLevel = 11
+
+// Validate reports why a combination of LogN, Level and Scale cannot be used by pp-stat, or nil if it can.
+func Validate(LogN, Level, Scale int, isBTS bool) error {
+	if LogN < rlwe.MinLogN || LogN > rlwe.MaxLogN {
+		return fmt.Errorf("invalid parameters: LogN=%d must be in [%d, %d]", LogN, rlwe.MinLogN, rlwe.MaxLogN)
+	}
+	if Level < 1 {
+		return fmt.Errorf("invalid parameters: Level=%d must be at least 1, as every multiplication consumes a level", Level)
+	}
+	if Scale < MinScale || Scale >= FirstModulusSize {
+		return fmt.Errorf("invalid parameters: Scale=%d must be in [%d, %d): below %d bits the CKKS error dominates, and the first modulus of %d bits must be larger than the scale",
+			Scale, MinScale, FirstModulusSize, MinScale, FirstModulusSize)
+	}
+	if isBTS && Level < MinBootstrapLevel {
+		return fmt.Errorf("invalid parameters: Level=%d is too small for bootstrapping: ciphertexts are bootstrapped to Level, and the inverse square root circuit with the statistics built on it needs %d levels",
+			Level, MinBootstrapLevel)
+	}
+	return nil
 }
 
-func NewParameters(LogN, Level, Scale int, isBTS bool) (bool, ckks.Parameters, bootstrapping.Parameters) {
+// NewParameters returns the CKKS parameters with a first modulus of FirstModulusSize bits followed by Level
+// moduli of Scale bits, and the bootstrapping parameters if isBTS is set. The combination is checked by Validate.
+func NewParameters(LogN, Level, Scale int, isBTS bool) (bool, ckks.Parameters, bootstrapping.Parameters, error) {
+	if err := Validate(LogN, Level, Scale, isBTS); err != nil {
+		return false, ckks.Parameters{}, bootstrapping.Parameters{}, err
+	}
+
 	LogQ := make([]int, Level+1)
-	LogQ[0] = 60
+	LogQ[0] = FirstModulusSize
 	for i := range Level {
 		LogQ[i+1] = Scale
 	}
@@ -28,7 +151,7 @@ func NewParameters(LogN, Level, Scale int, isBTS bool) (bool, ckks.Parameters, b
 			LogDefaultScale: Scale,                 // log2(scale)
 		})
 	if err != nil {
-		panic(err)
+		return false, ckks.Parameters{}, bootstrapping.Parameters{}, fmt.Errorf("ckks parameters: %w", err)
 	}
 
 	var btpParams bootstrapping.Parameters
@@ -41,7 +164,7 @@ func NewParameters(LogN, Level, Scale int, isBTS bool) (bool, ckks.Parameters, b
 		}
 		btpParams, err = bootstrapping.NewParametersFromLiteral(params, btpParametersLit)
 		if err != nil {
-			panic(err)
+			return false, ckks.Parameters{}, bootstrapping.Parameters{}, fmt.Errorf("bootstrapping parameters: %w", err)
 		}
 		fmt.Printf("Residual parameters: logN=%d, logSlots=%d, H=%d, sigma=%f, logQP=%f, levels=%d, scale=2^%d\n",
 			btpParams.ResidualParameters.LogN(),
@@ -61,8 +184,8 @@ func NewParameters(LogN, Level, Scale int, isBTS bool) (bool, ckks.Parameters, b
 			btpParams.BootstrappingParameters.QCount(),
 			btpParams.BootstrappingParameters.LogDefaultScale())
 
-		return isBTS, params, btpParams
+		return isBTS, params, btpParams, nil
 	} else {
-		return isBTS, params, btpParams
+		return isBTS, params, btpParams, nil
 	}
 }
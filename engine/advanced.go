@@ -1,13 +1,18 @@
 package engine
 
 import (
+	"context"
 	"fmt"
-
-	"github.com/tuneinsight/lattigo/v6/circuits/ckks/minimax"
-	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"math"
 )
 
-func (e *HEEngine) ZScoreNorm(ct *HEData, B float64) (*HEData, error) {
+func (e *Server) ZScoreNorm(ct *HEData, B float64) (*HEData, error) {
+	return e.ZScoreNormCtx(context.Background(), ct, B)
+}
+
+// ZScoreNormCtx is ZScoreNorm returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps or Newton iterations. Its progress is reported to e.Progress.
+func (e *Server) ZScoreNormCtx(ctx context.Context, ct *HEData, B float64) (*HEData, error) {
 	const (
 		chebyshevDegree = 2 // Degree for initial Chebyshev approximation
 		newtonIter      = 5 // Iteration count for Newton refinement
@@ -48,14 +53,14 @@ func (e *HEEngine) ZScoreNorm(ct *HEData, B float64) (*HEData, error) {
 
 	// Optional: Bootstrap the initial guess for higher precision
 	if e.IsBTS {
-		invSigmaInit, err = e.DoBootstrap(invSigmaInit, bootstrapDepth)
+		invSigmaInit, err = e.doBootstrap(ctx, invSigmaInit, bootstrapDepth)
 		if err != nil {
 			return nil, fmt.Errorf("bootstrap (invSqrt init): %w", err)
 		}
 	}
 
 	// Step 5: Refine variance and compute Newton-based 1/σ
-	varianceRefined, err := e.Variance(ct)
+	varianceRefined, err := e.Variance(ct, MomentOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("compute refined variance: %w", err)
 	}
@@ -64,13 +69,13 @@ func (e *HEEngine) ZScoreNorm(ct *HEData, B float64) (*HEData, error) {
 		return nil, fmt.Errorf("selectOneCtxt (variance refined): %w", err)
 	}
 
-	invSigmaRefined, err := e.HENewtonInv(varRefinedCtxt, invSigmaInit, B, newtonIter, newtonScale)
+	invSigmaRefined, err := e.newtonInv(ctx, varRefinedCtxt, invSigmaInit, B, newtonIter, newtonScale)
 	if err != nil {
 		return nil, fmt.Errorf("HENewtonInv: %w", err)
 	}
 
 	// Step 6: Extend 1/σ to all slots for element-wise multiplication
-	invSigmaSlots, err := e.extendOneToMulty(invSigmaRefined, len(centered.Ciphertexts()), centered.Size())
+	invSigmaSlots, err := e.extendOneToMulty(invSigmaRefined, centered.NumCiphertexts(), centered.Size())
 	if err != nil {
 		return nil, fmt.Errorf("extendOneToMulty: %w", err)
 	}
@@ -84,15 +89,29 @@ func (e *HEEngine) ZScoreNorm(ct *HEData, B float64) (*HEData, error) {
 	return zscore, nil
 }
 
-func (e *HEEngine) Kurtosis(ct *HEData, B float64) (*HEData, error) {
+// Kurtosis returns the excess kurtosis m4/m2² - 3 of ct, or G2 for the Sample estimator of opts,
+// replicated in every slot like Sum. 3 is added back if opts.Raw is set.
+func (e *Server) Kurtosis(ct *HEData, B float64, opts MomentOptions) (*HEData, error) {
+	return e.KurtosisCtx(context.Background(), ct, B, opts)
+}
+
+// KurtosisCtx is Kurtosis returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps or Newton iterations. Its progress is reported to e.Progress.
+func (e *Server) KurtosisCtx(ctx context.Context, ct *HEData, B float64, opts MomentOptions) (*HEData, error) {
 	const (
 		chebyshevDegree = 2
 		newtonIter      = 5
 		newtonScale     = 2
 		bootstrapDepth  = 3
-		subtractBias    = 3.0 // for excess kurtosis
 	)
 
+	n := float64(ct.Size())
+	if opts.Estimator == Sample && n < 4 {
+		return nil, fmt.Errorf("the sample kurtosis needs at least four values, got %v", n)
+	}
+	// the estimator is scale·m4/m2² + shift
+	scale, shift := opts.KurtosisAffine(n)
+
 	// Step 1: Compute mean μ
 	mean, err := e.Mean(ct)
 	if err != nil {
@@ -116,8 +135,8 @@ func (e *HEEngine) Kurtosis(ct *HEData, B float64) (*HEData, error) {
 		return nil, fmt.Errorf("x^4: %w", err)
 	}
 
-	// Step 4: Compute E[x⁴] (numerator)
-	numerator, err := e.Mean(x4)
+	// Step 4: Compute scale·E[x⁴] (numerator)
+	numerator, err := meanWithCustomDenom(e, x4, n/scale)
 	if err != nil {
 		return nil, fmt.Errorf("mean of x^4: %w", err)
 	}
@@ -141,14 +160,14 @@ func (e *HEEngine) Kurtosis(ct *HEData, B float64) (*HEData, error) {
 	}
 
 	if e.IsBTS {
-		invSigmaInit, err = e.DoBootstrap(invSigmaInit, bootstrapDepth)
+		invSigmaInit, err = e.doBootstrap(ctx, invSigmaInit, bootstrapDepth)
 		if err != nil {
 			return nil, fmt.Errorf("bootstrap (Chebyshev init): %w", err)
 		}
 	}
 
 	// Step 7: Refine 1/σ using Newton method
-	varianceRefined, err := e.Variance(ct)
+	varianceRefined, err := e.Variance(ct, MomentOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("variance (refined): %w", err)
 	}
@@ -158,7 +177,7 @@ func (e *HEEngine) Kurtosis(ct *HEData, B float64) (*HEData, error) {
 		return nil, fmt.Errorf("selectOneCtxt (variance refined): %w", err)
 	}
 
-	invSigma, err := e.HENewtonInv(varRefinedCtxt, invSigmaInit, B, newtonIter, newtonScale)
+	invSigma, err := e.newtonInv(ctx, varRefinedCtxt, invSigmaInit, B, newtonIter, newtonScale)
 	if err != nil {
 		return nil, fmt.Errorf("HENewtonInv: %w", err)
 	}
@@ -175,7 +194,7 @@ func (e *HEEngine) Kurtosis(ct *HEData, B float64) (*HEData, error) {
 	}
 
 	// Step 9: Apply inv(σ⁴) to numerator: E[x⁴] × (1/σ⁴)
-	invSigma4Expanded, err := e.extendOneToMulty(invSigma4, len(numerator.Ciphertexts()), numerator.Size())
+	invSigma4Expanded, err := e.extendOneToMulty(invSigma4, numerator.NumCiphertexts(), numerator.Size())
 	if err != nil {
 		return nil, fmt.Errorf("extendOneToMulty (inv σ⁴): %w", err)
 	}
@@ -185,16 +204,26 @@ func (e *HEEngine) Kurtosis(ct *HEData, B float64) (*HEData, error) {
 		return nil, fmt.Errorf("final multiply: %w", err)
 	}
 
-	// Step 10: Convert to excess kurtosis: K − 3
-	kurtosis, err = e.SubConst(kurtosis, subtractBias)
-	if err != nil {
-		return nil, fmt.Errorf("subtract bias: %w", err)
+	// Step 10: Add the shift, e.g. −3 for the excess kurtosis
+	if shift != 0 {
+		kurtosis, err = e.AddConst(kurtosis, shift)
+		if err != nil {
+			return nil, fmt.Errorf("add shift: %w", err)
+		}
 	}
 
 	return kurtosis, nil
 }
 
-func (e *HEEngine) Skewness(ct *HEData, B float64) (*HEData, error) {
+// Skewness returns the skewness m3/m2^(3/2) of ct, or the adjusted Fisher–Pearson skewness G1 for the
+// Sample estimator of opts, replicated in every slot like Sum.
+func (e *Server) Skewness(ct *HEData, B float64, opts MomentOptions) (*HEData, error) {
+	return e.SkewnessCtx(context.Background(), ct, B, opts)
+}
+
+// SkewnessCtx is Skewness returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps or Newton iterations. Its progress is reported to e.Progress.
+func (e *Server) SkewnessCtx(ctx context.Context, ct *HEData, B float64, opts MomentOptions) (*HEData, error) {
 	const (
 		chebyshevDegree = 2
 		newtonIter      = 5
@@ -202,6 +231,11 @@ func (e *HEEngine) Skewness(ct *HEData, B float64) (*HEData, error) {
 		bootstrapDepth  = 3
 	)
 
+	n := float64(ct.Size())
+	if opts.Estimator == Sample && n < 3 {
+		return nil, fmt.Errorf("the sample skewness needs at least three values, got %v", n)
+	}
+
 	// Step 1: Compute mean μ
 	mean, err := e.Mean(ct)
 	if err != nil {
@@ -225,8 +259,8 @@ func (e *HEEngine) Skewness(ct *HEData, B float64) (*HEData, error) {
 		return nil, fmt.Errorf("compute x^3: %w", err)
 	}
 
-	// Step 4: Compute E[x³]
-	numerator, err := e.Mean(x3)
+	// Step 4: Compute E[x³], multiplied by the factor of G1 for the Sample estimator
+	numerator, err := meanWithCustomDenom(e, x3, n/opts.SkewnessScale(n))
 	if err != nil {
 		return nil, fmt.Errorf("mean of x^3: %w", err)
 	}
@@ -250,14 +284,14 @@ func (e *HEEngine) Skewness(ct *HEData, B float64) (*HEData, error) {
 	}
 
 	if e.IsBTS {
-		invSigmaInit, err = e.DoBootstrap(invSigmaInit, bootstrapDepth)
+		invSigmaInit, err = e.doBootstrap(ctx, invSigmaInit, bootstrapDepth)
 		if err != nil {
 			return nil, fmt.Errorf("bootstrap (Chebyshev init): %w", err)
 		}
 	}
 
 	// Step 7: Refine inverse std dev using Newton
-	varianceRefined, err := e.Variance(ct)
+	varianceRefined, err := e.Variance(ct, MomentOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("variance (refined): %w", err)
 	}
@@ -267,7 +301,7 @@ func (e *HEEngine) Skewness(ct *HEData, B float64) (*HEData, error) {
 		return nil, fmt.Errorf("selectOneCtxt (variance refined): %w", err)
 	}
 
-	invSigma, err := e.HENewtonInv(varRefinedCtxt, invSigmaInit, B, newtonIter, newtonScale)
+	invSigma, err := e.newtonInv(ctx, varRefinedCtxt, invSigmaInit, B, newtonIter, newtonScale)
 	if err != nil {
 		return nil, fmt.Errorf("HENewtonInv: %w", err)
 	}
@@ -284,7 +318,7 @@ func (e *HEEngine) Skewness(ct *HEData, B float64) (*HEData, error) {
 	}
 
 	// Step 9: Final result: E[x³] × (1/σ³)
-	invSigma3Expanded, err := e.extendOneToMulty(invSigma3, len(numerator.Ciphertexts()), numerator.Size())
+	invSigma3Expanded, err := e.extendOneToMulty(invSigma3, numerator.NumCiphertexts(), numerator.Size())
 	if err != nil {
 		return nil, fmt.Errorf("extendOneToMulty: %w", err)
 	}
@@ -297,7 +331,15 @@ func (e *HEEngine) Skewness(ct *HEData, B float64) (*HEData, error) {
 	return skewness, nil
 }
 
-func (e *HEEngine) CoeffVar(ct *HEData, B float64) (*HEData, error) {
+// CoeffVar returns the coefficient of variation σ/μ of ct, replicated in every slot like Sum, where σ is
+// the square root of the Variance for the estimator of opts.
+func (e *Server) CoeffVar(ct *HEData, B float64, opts MomentOptions) (*HEData, error) {
+	return e.CoeffVarCtx(context.Background(), ct, B, opts)
+}
+
+// CoeffVarCtx is CoeffVar returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps or Newton iterations. Its progress is reported to e.Progress.
+func (e *Server) CoeffVarCtx(ctx context.Context, ct *HEData, B float64, opts MomentOptions) (*HEData, error) {
 	const (
 		chebyshevDegree = 2
 		newtonIter      = 2
@@ -306,7 +348,11 @@ func (e *HEEngine) CoeffVar(ct *HEData, B float64) (*HEData, error) {
 		bootstrapLevel  = 10
 	)
 
-	scaleBase := float64(ct.Size()) * B
+	n := float64(ct.Size())
+	if opts.Estimator == Sample && n < 2 {
+		return nil, fmt.Errorf("the sample variance needs at least two values, got %v", n)
+	}
+	scaleBase := n * B
 
 	// Step 1: Compute mean
 	mean, err := meanWithCustomDenom(e, ct, scaleBase)
@@ -315,27 +361,26 @@ func (e *HEEngine) CoeffVar(ct *HEData, B float64) (*HEData, error) {
 	}
 
 	// Step 2: Compute sign(mean) using minimax polynomial
-	polys := minimax.NewPolynomial(DefaultPolynomialForSign)
-	minimaxEval := minimax.NewEvaluator(e.params, e.evaluator, e.BTS)
-	signEvaluator := NewEvaluator(e.params, minimaxEval, polys)
-
-	signCtxt, err := signEvaluator.Sign(mean.Ciphertexts()[0])
+	meanCtxt, err := e.selectOneCtxt(mean)
+	if err != nil {
+		return nil, fmt.Errorf("selectOneCtxt(mean): %w", err)
+	}
+	sign, err := e.sign(ctx, meanCtxt)
 	if err != nil {
 		return nil, fmt.Errorf("sign(mean): %w", err)
 	}
 
 	// Step 3: Replicate sign across all slots
-	if signCtxt.Level() < bootstrapLevel && e.IsBTS {
-		signCtxt, err = e.BTS.Bootstrap(signCtxt)
+	if e.IsBTS {
+		sign, err = e.doBootstrap(ctx, sign, bootstrapLevel)
 		if err != nil {
 			return nil, fmt.Errorf("bootstrap sign(mean): %w", err)
 		}
 	}
-	signSlots := make([]*rlwe.Ciphertext, len(mean.Ciphertexts()))
-	for i := range signSlots {
-		signSlots[i] = signCtxt.CopyNew()
+	signMean, err := e.extendOneToMulty(sign, mean.NumCiphertexts(), mean.Size())
+	if err != nil {
+		return nil, fmt.Errorf("extendOneToMulty(sign): %w", err)
 	}
-	signMean := NewHEData(signSlots, mean.Size(), signCtxt.Level(), mean.Scale())
 
 	// Step 4: Compute |mean| = mean × sign(mean)
 	absMean, err := e.Mult(mean, signMean)
@@ -353,7 +398,7 @@ func (e *HEEngine) CoeffVar(ct *HEData, B float64) (*HEData, error) {
 		return nil, fmt.Errorf("ChebyshevInvSqrt(absMean): %w", err)
 	}
 	if e.IsBTS {
-		initInv, err = e.DoBootstrap(initInv, bootstrapDepth)
+		initInv, err = e.doBootstrap(ctx, initInv, bootstrapDepth)
 		if err != nil {
 			return nil, fmt.Errorf("bootstrap ChebyshevInvSqrt(mean): %w", err)
 		}
@@ -364,7 +409,7 @@ func (e *HEEngine) CoeffVar(ct *HEData, B float64) (*HEData, error) {
 		return nil, fmt.Errorf("square(ChebyshevInvSqrt): %w", err)
 	}
 
-	invAbsMean, err := e.HENewtonInv(absMeanCtxt, squareInv, B, newtonIter, newtonScale)
+	invAbsMean, err := e.newtonInv(ctx, absMeanCtxt, squareInv, B, newtonIter, newtonScale)
 	if err != nil {
 		return nil, fmt.Errorf("HENewtonInv(mean): %w", err)
 	}
@@ -379,7 +424,8 @@ func (e *HEEngine) CoeffVar(ct *HEData, B float64) (*HEData, error) {
 	}
 
 	// Step 6: Compute variance
-	variance, err := varianceWithCustomDenom(e, ct, scaleBase, scaleBase*B)
+	varScale := opts.VarianceScale(n)
+	variance, err := varianceWithCustomDenom(e, ct, scaleBase/math.Sqrt(varScale), scaleBase*B/varScale)
 	if err != nil {
 		return nil, fmt.Errorf("variance: %w", err)
 	}
@@ -394,7 +440,7 @@ func (e *HEEngine) CoeffVar(ct *HEData, B float64) (*HEData, error) {
 		return nil, fmt.Errorf("CryptoSqrt(variance): %w", err)
 	}
 	if e.IsBTS {
-		sqrtVar, err = e.DoBootstrap(sqrtVar, 2)
+		sqrtVar, err = e.doBootstrap(ctx, sqrtVar, 2)
 		if err != nil {
 			return nil, fmt.Errorf("bootstrap sqrt(variance): %w", err)
 		}
@@ -412,7 +458,7 @@ func (e *HEEngine) CoeffVar(ct *HEData, B float64) (*HEData, error) {
 		return nil, fmt.Errorf("final multiply (CV): %w", err)
 	}
 
-	cv, err = e.extendOneToMulty(cv, len(ct.ciphertexts), ct.Size())
+	cv, err = e.extendOneToMulty(cv, ct.NumCiphertexts(), ct.Size())
 	if err != nil {
 		return nil, fmt.Errorf("extendOneToMulty: %w", err)
 	}
@@ -420,7 +466,13 @@ func (e *HEEngine) CoeffVar(ct *HEData, B float64) (*HEData, error) {
 	return cv, nil
 }
 
-func (e *HEEngine) PCorrCoeff(ct1, ct2 *HEData, B float64) (*HEData, error) {
+func (e *Server) PCorrCoeff(ct1, ct2 *HEData, B float64) (*HEData, error) {
+	return e.PCorrCoeffCtx(context.Background(), ct1, ct2, B)
+}
+
+// PCorrCoeffCtx is PCorrCoeff returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps or Newton iterations. Its progress is reported to e.Progress.
+func (e *Server) PCorrCoeffCtx(ctx context.Context, ct1, ct2 *HEData, B float64) (*HEData, error) {
 	const (
 		chebyshevDegree = 2
 		newtonIter      = 6
@@ -459,29 +511,35 @@ func (e *HEEngine) PCorrCoeff(ct1, ct2 *HEData, B float64) (*HEData, error) {
 	}
 
 	// Step 4: Compute inverse std for X
-	invStdX, err := e.computeInvStd(ct1, chebyshevDegree, newtonIter, newtonScale, bootstrapDepth, B)
+	invStdX, err := e.computeInvStd(ctx, ct1, chebyshevDegree, newtonIter, newtonScale, bootstrapDepth, B)
 	if err != nil {
 		return nil, fmt.Errorf("computeInvStd (X): %w", err)
 	}
 
 	// Step 5: Compute inverse std for Y
-	invStdY, err := e.computeInvStd(ct2, chebyshevDegree, newtonIter, newtonScale, bootstrapDepth, B)
+	invStdY, err := e.computeInvStd(ctx, ct2, chebyshevDegree, newtonIter, newtonScale, bootstrapDepth, B)
 	if err != nil {
 		return nil, fmt.Errorf("computeInvStd (Y): %w", err)
 	}
 
 	// Step 6: Compute PCC = numerator × (1/σx) × (1/σy)
+	return e.normalizeCovariance(numerator, invStdX, invStdY)
+}
+
+// normalizeCovariance returns the correlation cov × (1/σx) × (1/σy), where invStdX and invStdY
+// are computed by computeInvStd.
+func (e *Server) normalizeCovariance(cov, invStdX, invStdY *HEData) (*HEData, error) {
 	denominator, err := e.Mult(invStdX, invStdY)
 	if err != nil {
 		return nil, fmt.Errorf("σx·σy inverse: %w", err)
 	}
 
-	denominatorExpanded, err := e.extendOneToMulty(denominator, len(numerator.Ciphertexts()), numerator.Size())
+	denominatorExpanded, err := e.extendOneToMulty(denominator, cov.NumCiphertexts(), cov.Size())
 	if err != nil {
 		return nil, fmt.Errorf("extendOneToMulty: %w", err)
 	}
 
-	pcc, err := e.Mult(numerator, denominatorExpanded)
+	pcc, err := e.Mult(cov, denominatorExpanded)
 	if err != nil {
 		return nil, fmt.Errorf("final multiply: %w", err)
 	}
@@ -489,7 +547,7 @@ func (e *HEEngine) PCorrCoeff(ct1, ct2 *HEData, B float64) (*HEData, error) {
 	return pcc, nil
 }
 
-func (e *HEEngine) computeInvStd(ct *HEData, chebDeg, newtonIter, newtonScale, bootstrapDepth int, B float64) (*HEData, error) {
+func (e *Server) computeInvStd(ctx context.Context, ct *HEData, chebDeg, newtonIter, newtonScale, bootstrapDepth int, B float64) (*HEData, error) {
 	denom := float64(ct.Size()) * B
 
 	// Approximate variance
@@ -508,14 +566,14 @@ func (e *HEEngine) computeInvStd(ct *HEData, chebDeg, newtonIter, newtonScale, b
 		return nil, fmt.Errorf("ChebyshevInvSqrt: %w", err)
 	}
 	if e.IsBTS {
-		invSigmaInit, err = e.DoBootstrap(invSigmaInit, bootstrapDepth)
+		invSigmaInit, err = e.doBootstrap(ctx, invSigmaInit, bootstrapDepth)
 		if err != nil {
 			return nil, fmt.Errorf("bootstrap: %w", err)
 		}
 	}
 
 	// Refined variance
-	varianceRefined, err := e.Variance(ct)
+	varianceRefined, err := e.Variance(ct, MomentOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("variance (refined): %w", err)
 	}
@@ -525,7 +583,7 @@ func (e *HEEngine) computeInvStd(ct *HEData, chebDeg, newtonIter, newtonScale, b
 	}
 
 	// Newton refinement
-	invStd, err := e.HENewtonInv(varRefinedCtxt, invSigmaInit, B, newtonIter, newtonScale)
+	invStd, err := e.newtonInv(ctx, varRefinedCtxt, invSigmaInit, B, newtonIter, newtonScale)
 	if err != nil {
 		return nil, fmt.Errorf("HENewtonInv: %w", err)
 	}
@@ -533,7 +591,7 @@ func (e *HEEngine) computeInvStd(ct *HEData, chebDeg, newtonIter, newtonScale, b
 	return invStd, nil
 }
 
-func meanWithCustomDenom(e *HEEngine, ct *HEData, denom float64) (*HEData, error) {
+func meanWithCustomDenom(e *Server, ct *HEData, denom float64) (*HEData, error) {
 	// Step 1: Divide by denominator
 	scaledCt, err := e.MultConst(ct, 1.0/denom)
 	if err != nil {
@@ -549,7 +607,7 @@ func meanWithCustomDenom(e *HEEngine, ct *HEData, denom float64) (*HEData, error
 	return mean, nil
 }
 
-func varianceWithCustomDenom(e *HEEngine, ct *HEData, xDenom, xSquareDenom float64) (*HEData, error) {
+func varianceWithCustomDenom(e *Server, ct *HEData, xDenom, xSquareDenom float64) (*HEData, error) {
 	// Step 1: Compute E[X]
 	meanXScaled, err := e.MultConst(ct, 1.0/xDenom)
 	if err != nil {
@@ -593,20 +651,14 @@ func varianceWithCustomDenom(e *HEEngine, ct *HEData, xDenom, xSquareDenom float
 	return variance, nil
 }
 
-func (e *HEEngine) selectOneCtxt(ct *HEData) (*HEData, error) {
+func (e *Server) selectOneCtxt(ct *HEData) (*HEData, error) {
 	size := ct.Size()
 	if size > e.params.MaxSlots() {
 		size = e.params.MaxSlots()
 	}
-	ctxt := make([]*rlwe.Ciphertext, 1)
-	ctxt[0] = ct.Ciphertexts()[0].CopyNew()
-	return NewHEData(ctxt, size, ct.Level(), ct.Scale()), nil
+	return e.replicate(ct, 1, size, ct.DirtyPadding())
 }
 
-func (e *HEEngine) extendOneToMulty(ct *HEData, num, size int) (*HEData, error) {
-	ctxts := make([]*rlwe.Ciphertext, num)
-	for i := 0; i < num; i++ {
-		ctxts[i] = ct.Ciphertexts()[0].CopyNew()
-	}
-	return NewHEData(ctxts, size, ctxts[0].Level(), ct.Scale()), nil
+func (e *Server) extendOneToMulty(ct *HEData, num, size int) (*HEData, error) {
+	return e.replicate(ct, num, size, true)
 }
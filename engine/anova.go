@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+)
+
+// ANOVAResult is the encrypted result of ANOVA. Its values are replicated in every slot like Sum.
+type ANOVAResult struct {
+	// Between is the between-group sum of squares Σ n[k]·(μ[k] - μ)².
+	Between *HEData
+	// Within is the within-group sum of squares Σ (x - μ[k])² over the rows of every group k.
+	Within *HEData
+	// F is the ratio (Between/(K-1))/(Within/(N-K)) of the mean squares, for K groups and N rows, with
+	// K-1 and N-K degrees of freedom.
+	F *HEData
+}
+
+// ANOVA is the one-way analysis of variance of values over the groups, which are encrypted 0/1 columns
+// like those of EncryptCategorical and must partition the rows. It extends TTest to more than two groups.
+//
+// With x = values/B, the between-group sum of squares divided by N·B² is Σ mean((x - μ)·g[k])²/p[k], where
+// p[k] is the fraction of the rows in the group k, inverted by the Newton inverse of CryptoInv as in MeanIf.
+// The within-group one is Variance(x) minus it, and it is inverted the same way for F. The values must lie
+// in [-B, B], every group must hold at least about 0.1% of the rows and the within-group sum of squares
+// must be at least about 0.1% of N·B². Bootstrapping is required.
+func (e *Server) ANOVA(values *HEData, groups []*HEData, B float64) (*ANOVAResult, error) {
+	return e.ANOVACtx(context.Background(), values, groups, B)
+}
+
+// ANOVACtx is ANOVA returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps or Newton iterations. Its progress is reported to e.Progress.
+func (e *Server) ANOVACtx(ctx context.Context, values *HEData, groups []*HEData, B float64) (*ANOVAResult, error) {
+	if !e.IsBTS {
+		return nil, fmt.Errorf("ANOVA requires bootstrapping")
+	}
+	if B <= 0 {
+		return nil, fmt.Errorf("bound B must be positive: %v", B)
+	}
+	n, k := float64(values.Size()), float64(len(groups))
+	if k < 2 {
+		return nil, fmt.Errorf("ANOVA needs at least two groups, got %d", len(groups))
+	}
+	if n <= k {
+		return nil, fmt.Errorf("ANOVA needs more rows than groups, got %d rows and %d groups", values.Size(), len(groups))
+	}
+	for i, group := range groups {
+		if group.Size() != values.Size() {
+			return nil, fmt.Errorf("group %d has %d rows but the values have %d", i, group.Size(), values.Size())
+		}
+	}
+
+	// Step 1: Total variance of x = values/B
+	x, err := e.MultConst(values, 1/B)
+	if err != nil {
+		return nil, fmt.Errorf("scale by 1/B: %w", err)
+	}
+	total, err := e.Variance(x, MomentOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("variance: %w", err)
+	}
+	if total, err = e.scalarOperand(ctx, total, 2); err != nil {
+		return nil, fmt.Errorf("variance: %w", err)
+	}
+
+	// Step 2: Between-group variance Σ mean((x - μ)·g[k])²/p[k]
+	centered, err := e.centerColumns([]*HEData{x})
+	if err != nil {
+		return nil, err
+	}
+	if centered[0], err = e.doBootstrap(ctx, centered[0], 1); err != nil {
+		return nil, fmt.Errorf("bootstrap x - μ: %w", err)
+	}
+	var between *HEData
+	for i, group := range groups {
+		frac, err := e.Mean(group)
+		if err != nil {
+			return nil, fmt.Errorf("fraction of group %d: %w", i, err)
+		}
+		if frac, err = e.scalarOperand(ctx, frac, 2); err != nil {
+			return nil, fmt.Errorf("fraction of group %d: %w", i, err)
+		}
+		invFrac, err := e.invFraction(ctx, frac)
+		if err != nil {
+			return nil, fmt.Errorf("inverse fraction of group %d: %w", i, err)
+		}
+		masked, err := e.Mult(centered[0], group)
+		if err != nil {
+			return nil, fmt.Errorf("(x - μ)·g[%d]: %w", i, err)
+		}
+		shift, err := e.Mean(masked)
+		if err != nil {
+			return nil, fmt.Errorf("mean of (x - μ)·g[%d]: %w", i, err)
+		}
+		term, err := e.scalarMult(ctx, shift, shift)
+		if err != nil {
+			return nil, fmt.Errorf("square of group %d: %w", i, err)
+		}
+		if term, err = e.scalarMult(ctx, term, invFrac); err != nil {
+			return nil, fmt.Errorf("divide by the fraction of group %d: %w", i, err)
+		}
+		if between == nil {
+			between = term
+		} else if between, err = e.Add(between, term); err != nil {
+			return nil, fmt.Errorf("accumulate group %d: %w", i, err)
+		}
+	}
+
+	// Step 3: Within-group variance and F = (N-K)/(K-1)·between/within
+	within, err := e.Sub(total, between)
+	if err != nil {
+		return nil, fmt.Errorf("within-group variance: %w", err)
+	}
+	invWithin, err := e.invFraction(ctx, within)
+	if err != nil {
+		return nil, fmt.Errorf("inverse within-group variance: %w", err)
+	}
+	f, err := e.scalarMult(ctx, between, invWithin)
+	if err != nil {
+		return nil, fmt.Errorf("between/within: %w", err)
+	}
+
+	// Step 4: Scale the variances to sums of squares and replicate the results like Sum
+	result := &ANOVAResult{}
+	if result.Between, err = e.replicateScaled(ctx, between, n*B*B, values); err != nil {
+		return nil, fmt.Errorf("between-group sum of squares: %w", err)
+	}
+	if result.Within, err = e.replicateScaled(ctx, within, n*B*B, values); err != nil {
+		return nil, fmt.Errorf("within-group sum of squares: %w", err)
+	}
+	if result.F, err = e.replicateScaled(ctx, f, (n-k)/(k-1), values); err != nil {
+		return nil, fmt.Errorf("F ratio: %w", err)
+	}
+	return result, nil
+}
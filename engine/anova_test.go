@@ -0,0 +1,56 @@
+package engine_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hm-choi/pp-stat/utils"
+)
+
+func TestSimANOVA(t *testing.T) {
+	e := newSimEngine(t, true)
+	const B = 10.0
+	// Three groups of different sizes whose means differ by about one standard deviation.
+	values := uniform(300, 6, 27)
+	labels := make([]string, len(values))
+	for i := range values {
+		switch {
+		case i%6 == 0:
+			labels[i] = "a"
+		case i%6 < 3:
+			labels[i] = "b"
+			values[i] += 1.5
+		default:
+			labels[i] = "c"
+			values[i] += 3
+		}
+	}
+	ct, err := e.Encrypt(values)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	_, groups, err := e.EncryptCategorical(labels)
+	if err != nil {
+		t.Fatalf("EncryptCategorical: %v", err)
+	}
+
+	_, plainGroups := utils.OneHot(labels)
+	between, within, f := utils.ANOVA(values, plainGroups)
+	result, err := e.ANOVA(ct, groups, B)
+	if err != nil {
+		t.Fatalf("ANOVA: %v", err)
+	}
+	for _, tc := range []struct {
+		name string
+		got  []float64
+		want float64
+	}{
+		{"between", decryptSim(t, e, result.Between, nil), between},
+		{"within", decryptSim(t, e, result.Within, nil), within},
+		{"F", decryptSim(t, e, result.F, nil), f},
+	} {
+		if math.Abs(tc.got[0]-tc.want) > 1e-3*tc.want {
+			t.Errorf("%s = %v, want %v", tc.name, tc.got[0], tc.want)
+		}
+	}
+}
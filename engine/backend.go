@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// Backend evaluates the primitive operations on which the Server builds the statistics.
+//
+// A Backend computes the ciphertexts of a result and their level. The Server checks the
+// operands and keeps the rest of the HEData metadata: size, padding and fingerprint.
+// NewServer uses the Lattigo backend, which evaluates CKKS ciphertexts, and NewSimServer
+// a Simulator, which evaluates the same circuits on plaintext slots.
+type Backend interface {
+	// Add and Sub evaluate a+b and a-b slot-wise. The ciphertexts of the longer operand
+	// without counterpart are copied as is.
+	Add(a, b *HEData) (*HEData, error)
+	Sub(a, b *HEData) (*HEData, error)
+
+	// AddConst adds con to every slot.
+	AddConst(a *HEData, con float64) (*HEData, error)
+
+	// Mult multiplies a and b slot-wise, then relinearizes and rescales, which consumes one level.
+	Mult(a, b *HEData) (*HEData, error)
+
+	// MultInt multiplies every slot by con without consuming a level.
+	MultInt(a *HEData, con int) (*HEData, error)
+
+	// MultMask multiplies the a.Size() first slots by con and the padding slots by zero,
+	// then rescales, which consumes one level.
+	MultMask(a *HEData, con float64) (*HEData, error)
+
+	// MultVec multiplies the i-th ciphertext of a slot-wise by vecs[i], then rescales, which consumes one level.
+	MultVec(a *HEData, vecs [][]float64) (*HEData, error)
+
+	// AddVec adds vecs[i] to the i-th ciphertext of a slot-wise.
+	AddVec(a *HEData, vecs [][]float64) (*HEData, error)
+
+	// Rotate rotates the slots of every ciphertext of a cyclically by k positions to the left,
+	// so that slot i receives slot i+k. k may be negative.
+	Rotate(a *HEData, k int) (*HEData, error)
+
+	// Sum adds all the slots of all the ciphertexts and replicates the sum in every slot.
+	Sum(a *HEData) (*HEData, error)
+
+	// Replicate returns n copies of the first ciphertext of a.
+	Replicate(a *HEData, n int) (*HEData, error)
+
+	// Bootstrap refreshes every ciphertext of a to the maximum level, keeping the real part of the slots.
+	// The scale of a is reset to the default scale, as in DoBootstrap.
+	Bootstrap(a *HEData) (*HEData, error)
+
+	// EvalPoly evaluates poly on every slot of a, keeping the real part. It consumes poly.Depth() levels.
+	EvalPoly(a *HEData, poly bignum.Polynomial) (*HEData, error)
+
+	// EvalComposite evaluates the composition polys[n-1](...polys[0](x)) on every slot of a and
+	// bootstraps between two polynomials when the level left is too small. onBootstrap, if not nil,
+	// is called with the level after each of these bootstraps; an error it returns stops the evaluation.
+	EvalComposite(a *HEData, polys []bignum.Polynomial, onBootstrap func(level int) error) (*HEData, error)
+}
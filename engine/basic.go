@@ -5,270 +5,175 @@ import (
 	"math"
 
 	"github.com/hm-choi/pp-stat/utils"
-	"github.com/tuneinsight/lattigo/v6/core/rlwe"
 )
 
 // Add performs element-wise homomorphic addition on two HEData inputs.
 // It returns a new HEData object with the result.
 // The function supports ciphertext slices of different lengths by padding the shorter one.
-func (e *HEEngine) Add(ct1, ct2 *HEData) (*HEData, error) {
-	// Determine output metadata: size, level, scale
-	size := max(ct1.Size(), ct2.Size())
-	level := min(ct1.Level(), ct2.Level())
-
+func (e *Server) Add(ct1, ct2 *HEData) (*HEData, error) {
 	// Ensure both ciphertexts have the same scale
 	if ct1.Scale() != ct2.Scale() {
 		return nil, fmt.Errorf("scale mismatch: %f vs %f", ct1.Scale(), ct2.Scale())
 	}
-	scale := math.Min(ct1.Scale(), ct2.Scale())
-
-	// Get ciphertext slices
-	ctxts1 := ct1.Ciphertexts()
-	ctxts2 := ct2.Ciphertexts()
-	ctLen1 := len(ctxts1)
-	ctLen2 := len(ctxts2)
-	ctNum := max(ctLen1, ctLen2)
-
-	// Prepare output slice
-	result := make([]*rlwe.Ciphertext, ctNum)
-
-	// Perform element-wise addition
-	for i := 0; i < ctNum; i++ {
-		switch {
-		case i < ctLen1 && i < ctLen2:
-			// Both slices have ciphertext at index i
-			ct, err := e.Evaluator().AddNew(ctxts1[i], ctxts2[i])
-			if err != nil {
-				return nil, fmt.Errorf("AddNew failed at index %d: %w", i, err)
-			}
-			result[i] = ct
-
-		case i >= ctLen1:
-			// Only ct2 has a ciphertext
-			result[i] = ctxts2[i]
-
-		case i >= ctLen2:
-			// Only ct1 has a ciphertext
-			result[i] = ctxts1[i]
-		}
-	}
 
-	return NewHEData(result, size, level, scale), nil
+	result, err := e.backend.Add(ct1, ct2)
+	if err != nil {
+		return nil, err
+	}
+	return e.result(result, max(ct1.Size(), ct2.Size()), ct1.DirtyPadding() || ct2.DirtyPadding()), nil
 }
 
-func (e *HEEngine) AddConst(ct *HEData, con float64) (*HEData, error) {
-	// Determine output metadata: size, level, scale
-	size := ct.Size()
-	level := ct.Level()
-	scale := ct.Scale()
-	ctNum := len(ct.Ciphertexts())
-
-	// Prepare output slice
-	ctxts := make([]*rlwe.Ciphertext, ctNum)
-
-	// Perform element-wise addition
-	for i := 0; i < ctNum; i++ {
-		ct, err := e.Evaluator().AddNew(ct.Ciphertexts()[i], con)
-		if err != nil {
-			return nil, fmt.Errorf("substraction failed at index %d: %w", i, err)
-		}
-		ctxts[i] = ct
+// AddConst adds con to every slot. The padding slots become non-zero, so the result is marked as having a dirty padding.
+func (e *Server) AddConst(ct *HEData, con float64) (*HEData, error) {
+	result, err := e.backend.AddConst(ct, con)
+	if err != nil {
+		return nil, err
 	}
-
-	return NewHEData(ctxts, size, level, scale), nil
+	return e.result(result, ct.Size(), true), nil
 }
 
 // Sub performs element-wise homomorphic subtraction on two HEData inputs.
 // It returns a new HEData object with the result.
 // The function supports ciphertext slices of different lengths by padding the shorter one.
-func (e *HEEngine) Sub(ct1, ct2 *HEData) (*HEData, error) {
-	// Determine output metadata: size, level, scale
-	size := max(ct1.Size(), ct2.Size())
-	level := min(ct1.Level(), ct2.Level())
-
+func (e *Server) Sub(ct1, ct2 *HEData) (*HEData, error) {
 	// Ensure both ciphertexts have the same scale
 	if ct1.Scale() != ct2.Scale() {
 		return nil, fmt.Errorf("scale mismatch: %f vs %f", ct1.Scale(), ct2.Scale())
 	}
-	scale := math.Min(ct1.Scale(), ct2.Scale())
-
-	// Get ciphertext slices
-	ctxts1 := ct1.Ciphertexts()
-	ctxts2 := ct2.Ciphertexts()
-	ctLen1 := len(ctxts1)
-	ctLen2 := len(ctxts2)
-	ctNum := max(ctLen1, ctLen2)
-
-	// Prepare output slice
-	result := make([]*rlwe.Ciphertext, ctNum)
-
-	// Perform element-wise addition
-	for i := 0; i < ctNum; i++ {
-		switch {
-		case i < ctLen1 && i < ctLen2:
-			// Both slices have ciphertext at index i
-			ct, err := e.Evaluator().SubNew(ctxts1[i], ctxts2[i])
-			if err != nil {
-				return nil, fmt.Errorf("SubNew failed at index %d: %w", i, err)
-			}
-			result[i] = ct
-
-		case i >= ctLen1:
-			// Only ct2 has a ciphertext
-			result[i] = ctxts2[i]
-
-		case i >= ctLen2:
-			// Only ct1 has a ciphertext
-			result[i] = ctxts1[i]
-		}
-	}
 
-	return NewHEData(result, size, level, scale), nil
+	result, err := e.backend.Sub(ct1, ct2)
+	if err != nil {
+		return nil, err
+	}
+	return e.result(result, max(ct1.Size(), ct2.Size()), ct1.DirtyPadding() || ct2.DirtyPadding()), nil
 }
 
-func (e *HEEngine) SubConst(ct *HEData, con float64) (*HEData, error) {
-	// Determine output metadata: size, level, scale
-	size := ct.Size()
-	level := ct.Level()
-	scale := ct.Scale()
-	ctNum := len(ct.Ciphertexts())
-
-	// Prepare output slice
-	ctxts := make([]*rlwe.Ciphertext, ctNum)
-
-	// Perform element-wise addition
-	for i := 0; i < ctNum; i++ {
-		ct, err := e.Evaluator().SubNew(ct.Ciphertexts()[i], con)
-		if err != nil {
-			return nil, fmt.Errorf("substraction failed at index %d: %w", i, err)
-		}
-		ctxts[i] = ct
+// SubConst subtracts con from every slot. The padding slots become non-zero, so the result is marked as having a dirty padding.
+func (e *Server) SubConst(ct *HEData, con float64) (*HEData, error) {
+	result, err := e.backend.AddConst(ct, -con)
+	if err != nil {
+		return nil, fmt.Errorf("substraction failed: %w", err)
 	}
-
-	return NewHEData(ctxts, size, level, scale), nil
+	return e.result(result, ct.Size(), true), nil
 }
 
 // Mult performs element-wise homomorphic multiplication with relinearization and rescaling.
-func (e *HEEngine) Mult(ct1, ct2 *HEData) (*HEData, error) {
-	// Determine output metadata: size, level, scale
-	size := min(ct1.Size(), ct2.Size())
-	level := min(ct1.Level(), ct2.Level())
-
+// If AutoBootstrap is set, an operand without any level left is bootstrapped first.
+func (e *Server) Mult(ct1, ct2 *HEData) (*HEData, error) {
 	// Ensure both ciphertexts have the same scale
 	if ct1.Scale() != ct2.Scale() {
 		return nil, fmt.Errorf("scale mismatch: %f vs %f", ct1.Scale(), ct2.Scale())
 	}
-	scale := math.Min(ct1.Scale(), ct2.Scale())
 
-	if level < 1 {
+	var err error
+	if ct1, err = e.ensureLevel(ct1, 1); err != nil {
+		return nil, fmt.Errorf("bootstrap first operand: %w", err)
+	}
+	if ct2, err = e.ensureLevel(ct2, 1); err != nil {
+		return nil, fmt.Errorf("bootstrap second operand: %w", err)
+	}
+
+	if min(ct1.Level(), ct2.Level()) < 1 {
 		return nil, fmt.Errorf("level cannot be smaller than 1")
 	}
-	// Get ciphertext slices
-	ctxts1 := ct1.Ciphertexts()
-	ctxts2 := ct2.Ciphertexts()
-	ctLen1 := len(ctxts1)
-	ctLen2 := len(ctxts2)
-	ctNum := min(ctLen1, ctLen2)
-
-	// Prepare output slice
-	result := make([]*rlwe.Ciphertext, ctNum)
-
-	// Perform element-wise addition
-	for i := 0; i < ctNum; i++ {
-		ct1 := ctxts1[i].CopyNew()
-		ct2 := ctxts2[i].CopyNew()
-		ct, err := e.Evaluator().MulRelinNew(ct1, ct2)
-		if err != nil {
-			return nil, fmt.Errorf("MulRelinNew failed at index %d: %w", i, err)
-		}
-
-		// Rescale to default scale
-		if err = e.Evaluator().Rescale(ct, ct); err != nil {
-			return nil, fmt.Errorf("Rescale failed at index %d: %w", i, err)
-		}
-		result[i] = ct
+
+	result, err := e.backend.Mult(ct1, ct2)
+	if err != nil {
+		return nil, err
+	}
+	// A padding slot stays zero as soon as one of the operands is zero there.
+	return e.result(result, min(ct1.Size(), ct2.Size()), ct1.DirtyPadding() && ct2.DirtyPadding()), nil
+}
+
+// MultConst multiplies every slot by con.
+// Powers of two are applied as an integer scalar, which consumes no level and leaves the padding unchanged.
+// Other constants are multiplied as a vector that is zero on the padding slots, followed by a rescaling,
+// so the padding of the result is always clean; if AutoBootstrap is set, ct is bootstrapped first when no level is left.
+func (e *Server) MultConst(ct *HEData, con float64) (*HEData, error) {
+	if !utils.IsPowerOfTwo(con) {
+		return e.multMask(ct, con)
 	}
 
-	return NewHEData(result, size, level-1, scale), nil
+	result, err := e.backend.MultInt(ct, int(con))
+	if err != nil {
+		return nil, err
+	}
+	return e.result(result, ct.Size(), ct.DirtyPadding()), nil
 }
 
-// Mult performs element-wise homomorphic multiplication with relinearization and rescaling.
-func (e *HEEngine) MultConst(ct *HEData, con float64) (*HEData, error) {
-	// Determine output metadata: size, level, scale
-	size := ct.Size()
-	level := ct.Level()
-	scale := ct.Scale()
-	ctNum := len(ct.Ciphertexts())
-
-	// Prepare output slice
-	ctxts := make([]*rlwe.Ciphertext, ctNum)
-	skipRescale := utils.IsPowerOfTwo(con)
-
-	// Perform element-wise addition
-	SIZE := ct.Size()
-	// fmt.Println("SIZE", SIZE, e.params.MaxSlots())
-	for i := 0; i < ctNum; i++ {
-		consts := make([]float64, e.params.MaxSlots())
-		if SIZE >= e.params.MaxSlots() {
-			for j := 0; j < e.params.MaxSlots(); j++ {
-				consts[j] = con
-			}
-			SIZE -= e.params.MaxSlots()
-		} else {
-			for j := 0; j < SIZE; j++ {
-				consts[j] = con
-			}
-		}
-
-		ctNew, err := e.Evaluator().MulNew(ct.Ciphertexts()[i], consts)
-		if err != nil {
-			return nil, fmt.Errorf("MulNew failed at index %d: %w", i, err)
-		}
-		// Rescale to default scale
-		if !skipRescale {
-			if err = e.Evaluator().Rescale(ctNew, ctNew); err != nil {
-				return nil, fmt.Errorf("Rescale failed at index %d: %w", i, err)
-			}
-		}
-		ctxts[i] = ctNew
+// MaskPadding zeroes the padding slots of ct at the cost of one level.
+// It returns ct unchanged if its padding is already clean or if it has no padding slot.
+func (e *Server) MaskPadding(ct *HEData) (*HEData, error) {
+	if !ct.DirtyPadding() || ct.Size()%e.params.MaxSlots() == 0 {
+		return ct, nil
 	}
-	if !skipRescale {
-		level -= 1
+	return e.multMask(ct, 1.0)
+}
+
+// multMask multiplies the valid slots of ct by con and its padding slots by zero, then rescales.
+func (e *Server) multMask(ct *HEData, con float64) (*HEData, error) {
+	ct, err := e.ensureLevel(ct, 1)
+	if err != nil {
+		return nil, fmt.Errorf("bootstrap: %w", err)
 	}
 
-	return NewHEData(ctxts, size, level, scale), nil
+	result, err := e.backend.MultMask(ct, con)
+	if err != nil {
+		return nil, err
+	}
+	return e.result(result, ct.Size(), false), nil
 }
 
-// Sum performs a sum of all elements of input HEData.
-func (e *HEEngine) Sum(ct *HEData) (result *HEData, err error) {
-	ctxt := ct.Ciphertexts()[0].CopyNew()
-	if len(ct.Ciphertexts()) > 1 {
-		for i := 1; i < len(ct.Ciphertexts()); i++ {
-			e.evaluator.Add(ctxt, ct.Ciphertexts()[i], ctxt)
-		}
+// multVec multiplies the i-th ciphertext of ct slot-wise by vecs[i], then rescales.
+// dirty tells whether vecs may be non-zero on the padding slots.
+func (e *Server) multVec(ct *HEData, vecs [][]float64, dirty bool) (*HEData, error) {
+	ct, err := e.ensureLevel(ct, 1)
+	if err != nil {
+		return nil, fmt.Errorf("bootstrap: %w", err)
+	}
+
+	result, err := e.backend.MultVec(ct, vecs)
+	if err != nil {
+		return nil, err
+	}
+	return e.result(result, ct.Size(), dirty && ct.DirtyPadding()), nil
+}
+
+// addVec adds vecs[i] to the i-th ciphertext of ct slot-wise.
+// dirty tells whether vecs may be non-zero on the padding slots.
+func (e *Server) addVec(ct *HEData, vecs [][]float64, dirty bool) (*HEData, error) {
+	result, err := e.backend.AddVec(ct, vecs)
+	if err != nil {
+		return nil, err
 	}
+	return e.result(result, ct.Size(), dirty || ct.DirtyPadding()), nil
+}
 
-	for i := 0; i < e.params.LogMaxSlots(); i++ {
-		rot := 1 << i
-		tmp, err := e.evaluator.RotateNew(ctxt, rot)
-		if err != nil {
-			return nil, fmt.Errorf("rotation failed at %d: %w", rot, err)
-		}
-		if err = e.evaluator.Add(ctxt, tmp, ctxt); err != nil {
-			return nil, fmt.Errorf("addition failed: %w", err)
-		}
+// rotate rotates the slots of every ciphertext of ct cyclically by k positions to the left.
+// The padding slots receive valid slots, so the result is marked as having a dirty padding.
+func (e *Server) rotate(ct *HEData, k int) (*HEData, error) {
+	result, err := e.backend.Rotate(ct, k)
+	if err != nil {
+		return nil, err
 	}
+	return e.result(result, ct.Size(), true), nil
+}
 
-	ctxts := make([]*rlwe.Ciphertext, len(ct.Ciphertexts()))
-	for i := 0; i < len(ct.Ciphertexts()); i++ {
-		ctxts[i] = ctxt.CopyNew()
+// Sum performs a sum of all elements of input HEData.
+// A dirty padding is masked out first, so that only the Size valid slots are added.
+// The sum is replicated in every slot of the result.
+func (e *Server) Sum(ct *HEData) (result *HEData, err error) {
+	if ct, err = e.MaskPadding(ct); err != nil {
+		return nil, fmt.Errorf("mask padding: %w", err)
 	}
 
-	result = NewHEData(ctxts, ct.Size(), ct.Level(), ct.Scale())
-	return result, nil
+	if result, err = e.backend.Sum(ct); err != nil {
+		return nil, err
+	}
+	return e.result(result, ct.Size(), true), nil
 }
 
-func (e *HEEngine) Mean(ct *HEData) (result *HEData, err error) {
+func (e *Server) Mean(ct *HEData) (result *HEData, err error) {
 	sumCtxt, err := e.Sum(ct)
 	if err != nil {
 		return nil, fmt.Errorf("summation failed: %w", err)
@@ -276,7 +181,27 @@ func (e *HEEngine) Mean(ct *HEData) (result *HEData, err error) {
 	return e.MultConst(sumCtxt, 1.0/float64(ct.Size()))
 }
 
-func (e *HEEngine) Variance(ct *HEData) (result *HEData, err error) {
+// MomentOptions are the options of Variance, Skewness, Kurtosis and CoeffVar, which are those of
+// their plaintext references in utils. The zero value is the population estimator, with the excess kurtosis.
+type MomentOptions = utils.MomentOptions
+
+const (
+	// Population divides the central moments by N.
+	Population = utils.Population
+	// Sample is the unbiased variance, which divides by N-1, with the adjusted Fisher–Pearson
+	// skewness G1 and excess kurtosis G2 of the sample.
+	Sample = utils.Sample
+)
+
+// Variance returns the variance of ct, replicated in every slot like Sum: E[x²] - (E[x])² for the
+// Population estimator, and Σx²/(N-1) - (Σx)²/(N(N-1)) for the Sample one.
+func (e *Server) Variance(ct *HEData, opts MomentOptions) (result *HEData, err error) {
+	n := float64(ct.Size())
+	if opts.Estimator == Sample && n < 2 {
+		return nil, fmt.Errorf("the sample variance needs at least two values, got %v", n)
+	}
+	scale := opts.VarianceScale(n)
+
 	// Step 1: Compute x²
 	ctSquared, err := e.Mult(ct, ct)
 	if err != nil {
@@ -284,13 +209,13 @@ func (e *HEEngine) Variance(ct *HEData) (result *HEData, err error) {
 	}
 
 	// Step 2: Compute E[x²]
-	meanXSquared, err := e.Mean(ctSquared)
+	meanXSquared, err := meanWithCustomDenom(e, ctSquared, n/scale)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compute mean of x²: %w", err)
 	}
 
 	// Step 3: Compute E[x]
-	meanX, err := e.Mean(ct)
+	meanX, err := meanWithCustomDenom(e, ct, n/math.Sqrt(scale))
 	if err != nil {
 		return nil, fmt.Errorf("failed to compute mean of x: %w", err)
 	}
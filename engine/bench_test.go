@@ -0,0 +1,32 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/hm-choi/pp-stat/config"
+	"github.com/hm-choi/pp-stat/engine"
+)
+
+// BenchmarkSkewness measures the Throughput of config.Test, the number of values per second of
+// the Skewness of a column of 8192 values on a single core.
+func BenchmarkSkewness(b *testing.B) {
+	p := config.Test
+	p.Insecure = true
+	e, err := engine.NewHEEngineFromParameters(p)
+	if err != nil {
+		b.Fatalf("NewHEEngineFromParameters: %v", err)
+	}
+	const n, B = 8192, 20.0
+	ct, err := e.Encrypt(uniform(n, B, 5))
+	if err != nil {
+		b.Fatalf("Encrypt: %v", err)
+	}
+
+	b.ResetTimer()
+	for range b.N {
+		if _, err = e.Skewness(ct, B, engine.MomentOptions{}); err != nil {
+			b.Fatalf("Skewness: %v", err)
+		}
+	}
+	b.ReportMetric(float64(n*b.N)/b.Elapsed().Seconds(), "values/s")
+}
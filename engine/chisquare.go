@@ -0,0 +1,151 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+)
+
+// ChiSquareResult is the encrypted result of ChiSquare. Its values are replicated in every slot like Sum.
+type ChiSquareResult struct {
+	// Observed holds the contingency table: Observed[i][j] counts the rows in category i of a and j of b.
+	Observed [][]*HEData
+	// Expected holds the counts N·p[i]·q[j] expected under independence, where p and q are the fractions
+	// of the rows in the categories of a and b.
+	Expected [][]*HEData
+	// Statistic is χ² = Σ (Observed - Expected)²/Expected, with (len(a)-1)·(len(b)-1) degrees of freedom.
+	Statistic *HEData
+}
+
+// ChiSquare is Pearson's chi-square test of independence of two categorical columns, each encrypted as
+// the 0/1 indicator columns of its categories, like those of EncryptCategorical. The contingency table
+// is computed from the Mean of the products of the indicator columns of a and b.
+//
+// Every term (O - E)²/E is computed as N·(O/N - p·q)²·(1/p)·(1/q), where the inverses of the fractions
+// p and q of the categories are the Newton inverse of CryptoInv, as in MeanIf: every category must hold
+// at least about 0.1% of the rows. Bootstrapping is required.
+func (e *Server) ChiSquare(a, b []*HEData) (*ChiSquareResult, error) {
+	return e.ChiSquareCtx(context.Background(), a, b)
+}
+
+// ChiSquareCtx is ChiSquare returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps or Newton iterations. Its progress is reported to e.Progress.
+func (e *Server) ChiSquareCtx(ctx context.Context, a, b []*HEData) (*ChiSquareResult, error) {
+	if !e.IsBTS {
+		return nil, fmt.Errorf("the chi-square test requires bootstrapping")
+	}
+	if len(a) < 2 || len(b) < 2 {
+		return nil, fmt.Errorf("each column needs at least two categories, got %d and %d", len(a), len(b))
+	}
+	like := a[0]
+	for _, ct := range append(append([]*HEData{}, a...), b...) {
+		if ct.Size() != like.Size() {
+			return nil, fmt.Errorf("indicator columns have %d and %d rows", like.Size(), ct.Size())
+		}
+	}
+	n := float64(like.Size())
+
+	// Step 1: Fractions p[i], q[j] of the categories and their inverses
+	fractions := func(cols []*HEData, name string) ([]*HEData, []*HEData, error) {
+		frac := make([]*HEData, len(cols))
+		inv := make([]*HEData, len(cols))
+		for i, ct := range cols {
+			mean, err := e.Mean(ct)
+			if err != nil {
+				return nil, nil, fmt.Errorf("fraction of %s[%d]: %w", name, i, err)
+			}
+			if frac[i], err = e.scalarOperand(ctx, mean, 2); err != nil {
+				return nil, nil, fmt.Errorf("fraction of %s[%d]: %w", name, i, err)
+			}
+			if inv[i], err = e.invFraction(ctx, frac[i]); err != nil {
+				return nil, nil, fmt.Errorf("inverse fraction of %s[%d]: %w", name, i, err)
+			}
+		}
+		return frac, inv, nil
+	}
+	p, invP, err := fractions(a, "a")
+	if err != nil {
+		return nil, err
+	}
+	q, invQ, err := fractions(b, "b")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ChiSquareResult{Observed: make([][]*HEData, len(a)), Expected: make([][]*HEData, len(a))}
+	var stat *HEData
+	for i := range a {
+		result.Observed[i] = make([]*HEData, len(b))
+		result.Expected[i] = make([]*HEData, len(b))
+		for j := range b {
+			// Step 2: Observed fraction O/N = mean(a[i]·b[j]) and expected fraction p[i]·q[j]
+			both, err := e.Mult(a[i], b[j])
+			if err != nil {
+				return nil, fmt.Errorf("a[%d]·b[%d]: %w", i, j, err)
+			}
+			observed, err := e.Mean(both)
+			if err != nil {
+				return nil, fmt.Errorf("mean of a[%d]·b[%d]: %w", i, j, err)
+			}
+			if observed, err = e.scalarOperand(ctx, observed, 2); err != nil {
+				return nil, fmt.Errorf("observed fraction (%d, %d): %w", i, j, err)
+			}
+			expected, err := e.scalarMult(ctx, p[i], q[j])
+			if err != nil {
+				return nil, fmt.Errorf("expected fraction (%d, %d): %w", i, j, err)
+			}
+
+			// Step 3: (O/N - p·q)²·(1/p)·(1/q)
+			diff, err := e.Sub(observed, expected)
+			if err != nil {
+				return nil, fmt.Errorf("O - E (%d, %d): %w", i, j, err)
+			}
+			term, err := e.scalarMult(ctx, diff, diff)
+			if err != nil {
+				return nil, fmt.Errorf("(O - E)² (%d, %d): %w", i, j, err)
+			}
+			if term, err = e.scalarMult(ctx, term, invP[i]); err != nil {
+				return nil, fmt.Errorf("divide by p[%d]: %w", i, err)
+			}
+			if term, err = e.scalarMult(ctx, term, invQ[j]); err != nil {
+				return nil, fmt.Errorf("divide by q[%d]: %w", j, err)
+			}
+			if stat == nil {
+				stat = term
+			} else if stat, err = e.Add(stat, term); err != nil {
+				return nil, fmt.Errorf("accumulate (%d, %d): %w", i, j, err)
+			}
+
+			// Step 4: Counts, replicated like Sum
+			if result.Observed[i][j], err = e.replicateScaled(ctx, observed, n, like); err != nil {
+				return nil, fmt.Errorf("observed count (%d, %d): %w", i, j, err)
+			}
+			if result.Expected[i][j], err = e.replicateScaled(ctx, expected, n, like); err != nil {
+				return nil, fmt.Errorf("expected count (%d, %d): %w", i, j, err)
+			}
+		}
+	}
+
+	// Step 5: χ² = N·Σ (O/N - p·q)²/(p·q)
+	if stat, err = e.scalarOperand(ctx, stat, 1); err != nil {
+		return nil, fmt.Errorf("χ²: %w", err)
+	}
+	if stat, err = e.MultConst(stat, n); err != nil {
+		return nil, fmt.Errorf("χ²: %w", err)
+	}
+	if result.Statistic, err = e.extendOneToMulty(stat, like.NumCiphertexts(), like.Size()); err != nil {
+		return nil, fmt.Errorf("extendOneToMulty: %w", err)
+	}
+	return result, nil
+}
+
+// replicateScaled returns c·x, on the first ciphertext of the replicated value x, replicated to the size of like.
+func (e *Server) replicateScaled(ctx context.Context, x *HEData, c float64, like *HEData) (*HEData, error) {
+	x, err := e.scalarOperand(ctx, x, 1)
+	if err != nil {
+		return nil, err
+	}
+	if x, err = e.MultConst(x, c); err != nil {
+		return nil, err
+	}
+	return e.extendOneToMulty(x, like.NumCiphertexts(), like.Size())
+}
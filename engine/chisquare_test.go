@@ -0,0 +1,54 @@
+package engine_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hm-choi/pp-stat/utils"
+)
+
+func TestSimChiSquare(t *testing.T) {
+	e := newSimEngine(t, true)
+	// Two dependent categorical columns: b leans to the higher levels on the yes rows of a.
+	u, v := uniform(300, 1, 25), uniform(300, 3, 26)
+	labelsA, labelsB := make([]string, len(u)), make([]string, len(u))
+	for i := range u {
+		labelsA[i] = "no"
+		if u[i] < 0.4 {
+			labelsA[i] = "yes"
+			v[i] = math.Min(v[i]+0.8, 2.9)
+		}
+		labelsB[i] = []string{"low", "mid", "high"}[int(v[i])]
+	}
+	_, a, err := e.EncryptCategorical(labelsA)
+	if err != nil {
+		t.Fatalf("EncryptCategorical: %v", err)
+	}
+	_, b, err := e.EncryptCategorical(labelsB)
+	if err != nil {
+		t.Fatalf("EncryptCategorical: %v", err)
+	}
+
+	_, plainA := utils.OneHot(labelsA)
+	_, plainB := utils.OneHot(labelsB)
+	observed, statistic := utils.ChiSquare(plainA, plainB)
+	result, err := e.ChiSquare(a, b)
+	if err != nil {
+		t.Fatalf("ChiSquare: %v", err)
+	}
+	n := float64(len(u))
+	for i := range observed {
+		for j := range observed[i] {
+			if got := decryptSim(t, e, result.Observed[i][j], nil)[0]; math.Abs(got-observed[i][j]) > 1e-2 {
+				t.Errorf("observed [%d][%d] = %v, want %v", i, j, got, observed[i][j])
+			}
+			expected := utils.Mean(plainA[i]) * utils.Mean(plainB[j]) * n
+			if got := decryptSim(t, e, result.Expected[i][j], nil)[0]; math.Abs(got-expected) > 1e-2 {
+				t.Errorf("expected [%d][%d] = %v, want %v", i, j, got, expected)
+			}
+		}
+	}
+	if got := decryptSim(t, e, result.Statistic, nil)[0]; math.Abs(got-statistic) > 1e-3*statistic {
+		t.Errorf("statistic = %v, want %v", got, statistic)
+	}
+}
@@ -0,0 +1,185 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/hm-choi/pp-stat/config"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+// Client is the data owner. It holds the secret key, encrypts the input columns,
+// decrypts the results and generates the EvaluationKeys handed to a Server.
+type Client struct {
+	params    ckks.Parameters
+	btpParams bootstrapping.Parameters
+	Sk        *rlwe.SecretKey
+	Pk        *rlwe.PublicKey
+	Encryptor *rlwe.Encryptor
+	Decryptor *rlwe.Decryptor
+	Encoder   *ckks.Encoder
+	slots     int
+	isBTS     bool
+
+	fingerprint Fingerprint
+	sim         *Simulator // set by NewSimHEEngine, which generates no key
+}
+
+// EvaluationKeys is the public key material a Server needs to evaluate the statistics.
+// It does not allow decryption.
+type EvaluationKeys struct {
+	Rlk *rlwe.RelinearizationKey
+	Gks []*rlwe.GaloisKey
+	Evk *bootstrapping.EvaluationKeys // nil if bootstrapping is disabled
+}
+
+// NewClient generates a fresh key pair for the given parameters.
+// Parameters estimated below 128-bit security are refused, see NewClientFromParameters to use them anyway.
+func NewClient(isBTS bool, params ckks.Parameters, btpParams bootstrapping.Parameters) (*Client, error) {
+	return newKeyClient(isBTS, params, btpParams, false)
+}
+
+// NewClientFromParameters generates a fresh key pair for the named parameter set p.
+// Insecure parameters are only accepted if p.Insecure is set.
+func NewClientFromParameters(p config.Parameters) (*Client, error) {
+	isBTS, params, btpParams, err := p.Build()
+	if err != nil {
+		return nil, err
+	}
+	return newKeyClient(isBTS, params, btpParams, p.Insecure)
+}
+
+// newKeyClient generates a fresh key pair, checking the security of the parameters unless insecure is set.
+func newKeyClient(isBTS bool, params ckks.Parameters, btpParams bootstrapping.Parameters, insecure bool) (*Client, error) {
+	if !insecure {
+		if err := config.CheckSecurity(isBTS, params, btpParams); err != nil {
+			return nil, err
+		}
+	}
+	kgen := rlwe.NewKeyGenerator(params)
+	sk, pk := kgen.GenKeyPairNew()
+	return newClient(isBTS, params, btpParams, sk, pk)
+}
+
+// newClient builds a Client around an existing key pair.
+func newClient(isBTS bool, params ckks.Parameters, btpParams bootstrapping.Parameters, sk *rlwe.SecretKey, pk *rlwe.PublicKey) (*Client, error) {
+	if err := checkParams(isBTS, params); err != nil {
+		return nil, err
+	}
+	fp, err := ParamsFingerprint(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		params:      params,
+		btpParams:   btpParams,
+		Sk:          sk,
+		Pk:          pk,
+		Encryptor:   rlwe.NewEncryptor(params, pk),
+		Decryptor:   rlwe.NewDecryptor(params, sk),
+		Encoder:     ckks.NewEncoder(params),
+		slots:       params.MaxSlots(),
+		isBTS:       isBTS,
+		fingerprint: fp,
+	}, nil
+}
+
+func (c *Client) Params() ckks.Parameters { return c.params }
+
+// Fingerprint returns the fingerprint of the client parameters.
+func (c *Client) Fingerprint() Fingerprint { return c.fingerprint }
+
+// GenEvaluationKeys generates the relinearization key, the Galois keys and,
+// if bootstrapping is enabled, the bootstrapping keys from the secret key.
+func (c *Client) GenEvaluationKeys() (*EvaluationKeys, error) {
+	kgen := rlwe.NewKeyGenerator(c.params)
+	evk := &EvaluationKeys{
+		Rlk: kgen.GenRelinearizationKeyNew(c.Sk),
+		Gks: kgen.GenGaloisKeysNew(galoisElements(c.params), c.Sk),
+	}
+	if c.isBTS {
+		btsEvk, _, err := c.btpParams.GenEvaluationKeys(c.Sk)
+		if err != nil {
+			return nil, fmt.Errorf("bootstrapping keys: %w", err)
+		}
+		evk.Evk = btsEvk
+	}
+	return evk, nil
+}
+
+func (c *Client) Encrypt(input []float64) (ctxt *HEData, err error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("input data size is zero: %w", err)
+	}
+
+	if c.sim != nil {
+		heData := c.sim.Encrypt(input)
+		heData.fingerprint = c.fingerprint
+		return heData, nil
+	}
+
+	dataSize := len(input)
+
+	ctxtNum := ((len(input) + c.slots - 1) / c.slots)
+
+	ciphertexts := make([]*rlwe.Ciphertext, ctxtNum)
+	for i := range ctxtNum {
+		start := i * c.slots
+		end := start + c.slots
+		if end > len(input) {
+			end = len(input)
+		}
+
+		pt := ckks.NewPlaintext(c.params, c.params.MaxLevel())
+		if err = c.Encoder.Encode(input[start:end], pt); err != nil {
+			return nil, fmt.Errorf("encoding failed: %w", err)
+		}
+		ctxt, err := c.Encryptor.EncryptNew(pt)
+		if err != nil {
+			return nil, fmt.Errorf("encryption failed: %w", err)
+		}
+		ciphertexts[i] = ctxt
+	}
+	heData := NewHEData(ciphertexts, dataSize, c.params.MaxLevel(), 60.0)
+	heData.fingerprint = c.fingerprint
+	return heData, nil
+}
+
+func (c *Client) Decrypt(ctxt *HEData) (output []float64, err error) {
+	if ctxt.Simulated() {
+		return decodeSim(ctxt)[:ctxt.Size()], nil
+	}
+
+	output = []float64{}
+	ctxts := ctxt.Ciphertexts()
+	for i := range len(ctxts) {
+		tmpSlice := make([]float64, c.params.MaxSlots())
+		if err = c.Encoder.Decode(c.Decryptor.DecryptNew(ctxts[i]), tmpSlice); err != nil {
+			return nil, fmt.Errorf("decoding failed: %w", err)
+		}
+		output = append(output, tmpSlice...)
+	}
+	return output[:ctxt.Size()], nil
+}
+
+func (c *Client) DecryptComplex(ctxt *HEData) (output []complex128, err error) {
+	if ctxt.Simulated() {
+		for _, v := range decodeSim(ctxt) {
+			output = append(output, complex(v, 0))
+		}
+		return output, nil
+	}
+
+	output = []complex128{}
+	ctxts := ctxt.Ciphertexts()
+	for i := range len(ctxts) {
+		tmpSlice := make([]complex128, c.params.MaxSlots())
+		if err = c.Encoder.Decode(c.Decryptor.DecryptNew(ctxts[i]), tmpSlice); err != nil {
+			return nil, fmt.Errorf("decoding failed: %w", err)
+		}
+		output = append(output, tmpSlice...)
+	}
+	return output, nil
+}
@@ -0,0 +1,205 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+)
+
+// Condition selects rows of a column for CountIf, SumIf and MeanIf. It is either an encrypted
+// predicate, i.e. a column holding 1 on the selected rows and 0 on the others, or the comparison
+// of an encrypted column with a plaintext threshold, which is turned into such a column with Step.
+type Condition struct {
+	mask      *HEData
+	column    *HEData
+	threshold float64
+	bound     float64
+	greater   bool
+}
+
+// Where selects the rows on which the encrypted predicate mask is 1. mask must hold 0 or 1 on every
+// row, like a yes/no column read by utils.ReadCSV or the result of Greater.
+func Where(mask *HEData) Condition {
+	return Condition{mask: mask}
+}
+
+// GreaterThan selects the rows of ct larger than threshold. The values of ct and threshold must lie in [-B, B].
+func GreaterThan(ct *HEData, threshold, B float64) Condition {
+	return Condition{column: ct, threshold: threshold, bound: B, greater: true}
+}
+
+// LessThan selects the rows of ct smaller than threshold. The values of ct and threshold must lie in [-B, B].
+func LessThan(ct *HEData, threshold, B float64) Condition {
+	return Condition{column: ct, threshold: threshold, bound: B}
+}
+
+// CountIf returns the number of rows selected by cond, replicated in every slot like Sum.
+func (e *Server) CountIf(cond Condition) (*HEData, error) {
+	return e.CountIfCtx(context.Background(), cond)
+}
+
+// CountIfCtx is CountIf returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps. Its progress is reported to e.Progress.
+func (e *Server) CountIfCtx(ctx context.Context, cond Condition) (*HEData, error) {
+	mask, err := e.conditionMask(ctx, cond)
+	if err != nil {
+		return nil, err
+	}
+	return e.Sum(mask)
+}
+
+// SumIf returns the sum of the rows of ct selected by cond, replicated in every slot like Sum.
+func (e *Server) SumIf(ct *HEData, cond Condition) (*HEData, error) {
+	return e.SumIfCtx(context.Background(), ct, cond)
+}
+
+// SumIfCtx is SumIf returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps. Its progress is reported to e.Progress.
+func (e *Server) SumIfCtx(ctx context.Context, ct *HEData, cond Condition) (*HEData, error) {
+	masked, err := e.selectRows(ctx, ct, cond)
+	if err != nil {
+		return nil, err
+	}
+	return e.Sum(masked)
+}
+
+// MeanIf returns the mean of the rows of ct selected by cond, replicated in every slot like Sum.
+// The mean of ct·mask is divided by the fraction of selected rows with the Newton inverse of
+// CryptoInv, which converges for fractions down to about 0.1%. At least one row must be selected.
+func (e *Server) MeanIf(ct *HEData, cond Condition) (*HEData, error) {
+	return e.MeanIfCtx(context.Background(), ct, cond)
+}
+
+// MeanIfCtx is MeanIf returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps or Newton iterations. Its progress is reported to e.Progress.
+func (e *Server) MeanIfCtx(ctx context.Context, ct *HEData, cond Condition) (*HEData, error) {
+	mask, err := e.conditionMask(ctx, cond)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 1: Mean of ct·mask
+	masked, err := e.selectRows(ctx, ct, Where(mask))
+	if err != nil {
+		return nil, err
+	}
+	if e.IsBTS {
+		if masked, err = e.doBootstrap(ctx, masked, 1); err != nil {
+			return nil, fmt.Errorf("bootstrap ct·mask: %w", err)
+		}
+	}
+	maskedMean, err := e.Mean(masked)
+	if err != nil {
+		return nil, fmt.Errorf("mean of ct·mask: %w", err)
+	}
+
+	// Step 2: Fraction of selected rows
+	frac, err := e.Mean(mask)
+	if err != nil {
+		return nil, fmt.Errorf("mean of mask: %w", err)
+	}
+	fracCtxt, err := e.selectOneCtxt(frac)
+	if err != nil {
+		return nil, fmt.Errorf("selectOneCtxt (fraction): %w", err)
+	}
+
+	// Step 3: 1/fraction
+	invFrac, err := e.invFraction(ctx, fracCtxt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 4: mean(ct·mask) / fraction
+	invFracExpanded, err := e.extendOneToMulty(invFrac, maskedMean.NumCiphertexts(), maskedMean.Size())
+	if err != nil {
+		return nil, fmt.Errorf("extendOneToMulty: %w", err)
+	}
+	if e.IsBTS {
+		if maskedMean, err = e.doBootstrap(ctx, maskedMean, 1); err != nil {
+			return nil, fmt.Errorf("bootstrap mean of ct·mask: %w", err)
+		}
+	}
+	return e.Mult(maskedMean, invFracExpanded)
+}
+
+// invFraction returns 1/x for x in (0, 1] with the Newton inverse of CryptoInv, whose initial guess is
+// the square of the Chebyshev approximation of 1/sqrt. It converges for x down to about 0.1%.
+func (e *Server) invFraction(ctx context.Context, x *HEData) (*HEData, error) {
+	const (
+		chebyshevDegree = 2
+		chebyshevDepth  = 9
+		newtonIter      = 4
+		newtonScale     = 1
+		bootstrapDepth  = 3
+	)
+
+	var err error
+	if e.IsBTS {
+		if x, err = e.doBootstrap(ctx, x, chebyshevDepth+1); err != nil {
+			return nil, fmt.Errorf("bootstrap fraction: %w", err)
+		}
+	}
+	invInit, err := e.ChebyshevInvSqrt(x, chebyshevDegree, 1.0)
+	if err != nil {
+		return nil, fmt.Errorf("ChebyshevInvSqrt: %w", err)
+	}
+	if e.IsBTS {
+		if invInit, err = e.doBootstrap(ctx, invInit, bootstrapDepth); err != nil {
+			return nil, fmt.Errorf("bootstrap (Chebyshev init): %w", err)
+		}
+	}
+	if invInit, err = e.Mult(invInit, invInit); err != nil {
+		return nil, fmt.Errorf("square of initial guess: %w", err)
+	}
+	inv, err := e.newtonInv(ctx, x, invInit, 1.0, newtonIter, newtonScale)
+	if err != nil {
+		return nil, fmt.Errorf("HENewtonInv: %w", err)
+	}
+	return inv, nil
+}
+
+// conditionMask returns the 0/1 column of the rows selected by cond, with at least two levels left if
+// it was computed by a comparison.
+func (e *Server) conditionMask(ctx context.Context, cond Condition) (*HEData, error) {
+	if cond.mask != nil {
+		return cond.mask, nil
+	}
+	if cond.column == nil {
+		return nil, fmt.Errorf("empty condition")
+	}
+
+	var mask *HEData
+	var err error
+	if cond.greater {
+		mask, err = e.GreaterThanConstCtx(ctx, cond.column, cond.threshold, cond.bound)
+	} else {
+		mask, err = e.LessThanConstCtx(ctx, cond.column, cond.threshold, cond.bound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("compare with %v: %w", cond.threshold, err)
+	}
+	if mask, err = e.doBootstrap(ctx, mask, 2); err != nil {
+		return nil, fmt.Errorf("bootstrap mask: %w", err)
+	}
+	return mask, nil
+}
+
+// selectRows returns ct·mask, where mask is the 0/1 column of the rows selected by cond.
+func (e *Server) selectRows(ctx context.Context, ct *HEData, cond Condition) (*HEData, error) {
+	mask, err := e.conditionMask(ctx, cond)
+	if err != nil {
+		return nil, err
+	}
+	if mask.Size() != ct.Size() {
+		return nil, fmt.Errorf("condition has %d rows but the column has %d", mask.Size(), ct.Size())
+	}
+	if e.IsBTS {
+		if ct, err = e.doBootstrap(ctx, ct, 1); err != nil {
+			return nil, fmt.Errorf("bootstrap column: %w", err)
+		}
+	}
+	masked, err := e.Mult(ct, mask)
+	if err != nil {
+		return nil, fmt.Errorf("ct·mask: %w", err)
+	}
+	return masked, nil
+}
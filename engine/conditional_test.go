@@ -0,0 +1,72 @@
+package engine_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hm-choi/pp-stat/engine"
+)
+
+func TestSimConditional(t *testing.T) {
+	e := newSimEngine(t, true)
+	const B, threshold = 10.0, 2.5
+	values := uniform(200, B, 8)
+	ct, err := e.Encrypt(values)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	var count, sum float64
+	for _, v := range values {
+		if v > threshold {
+			count++
+			sum += v
+		}
+	}
+	cond := engine.GreaterThan(ct, threshold, B)
+	for _, tc := range []struct {
+		name string
+		eval func() (*engine.HEData, error)
+		want float64
+	}{
+		{"CountIf", func() (*engine.HEData, error) { return e.CountIf(cond) }, count},
+		{"SumIf", func() (*engine.HEData, error) { return e.SumIf(ct, cond) }, sum},
+		{"MeanIf", func() (*engine.HEData, error) { return e.MeanIf(ct, cond) }, sum / count},
+	} {
+		d, err := tc.eval()
+		got := decryptSim(t, e, d, err)
+		if rel := math.Abs(got[0]-tc.want) / tc.want; rel > 1e-3 {
+			t.Errorf("%s: got %v, want %v", tc.name, got[0], tc.want)
+		}
+	}
+}
+
+// TestSimMeanIfFraction checks the Newton inverse of the fraction of selected rows down to 0.1%.
+func TestSimMeanIfFraction(t *testing.T) {
+	e := newSimEngine(t, true)
+	const n = 1000
+	values := uniform(n, 10, 9)
+	ct, err := e.Encrypt(values)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	for _, frac := range []float64{0.001, 0.01, 0.5} {
+		mask := make([]float64, n)
+		var sum float64
+		k := int(frac * n)
+		for i := range k {
+			mask[i] = 1
+			sum += values[i]
+		}
+		want := sum / float64(k)
+		cond, err := e.Encrypt(mask)
+		if err != nil {
+			t.Fatalf("Encrypt: %v", err)
+		}
+		d, err := e.MeanIf(ct, engine.Where(cond))
+		got := decryptSim(t, e, d, err)
+		if rel := math.Abs(got[0]-want) / want; rel > 1e-3 {
+			t.Errorf("fraction %v: got %v, want %v", frac, got[0], want)
+		}
+	}
+}
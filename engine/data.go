@@ -1,9 +1,15 @@
 package engine
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
 	"fmt"
+	"io"
+	"math"
 
 	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/utils/buffer"
 )
 
 type HEData struct {
@@ -11,6 +17,14 @@ type HEData struct {
 	size        int
 	level       int
 	scale       float64
+	fingerprint Fingerprint
+
+	// dirty is set when the padding slots, i.e. the slots of the last ciphertext
+	// after size, may hold non-zero values. Reductions mask them out first.
+	dirty bool
+
+	// sim holds the ciphertexts of data produced by a Simulator, in which case ciphertexts is empty.
+	sim []*simCiphertext
 }
 
 func (d *HEData) Size() int                       { return d.size }
@@ -18,6 +32,15 @@ func (d *HEData) Level() int                      { return d.level }
 func (d *HEData) Scale() float64                  { return d.scale }
 func (d *HEData) Ciphertexts() []*rlwe.Ciphertext { return d.ciphertexts }
 
+// NumCiphertexts returns the number of ciphertexts, real or simulated, holding the data.
+func (d *HEData) NumCiphertexts() int { return len(d.ciphertexts) + len(d.sim) }
+
+// Simulated reports whether the data was produced by a Simulator. Simulated data has no Ciphertexts.
+func (d *HEData) Simulated() bool { return d.sim != nil }
+
+// DirtyPadding reports whether the padding slots after Size may hold non-zero values.
+func (d *HEData) DirtyPadding() bool { return d.dirty }
+
 func NewHEData(ciphertexts []*rlwe.Ciphertext, size int, level int, scale float64) *HEData {
 	return &HEData{
 		ciphertexts: ciphertexts,
@@ -39,9 +62,257 @@ func (d *HEData) CopyData() (cpData *HEData) {
 		cpCtxts[i] = ctxts[i].CopyNew()
 	}
 
-	return NewHEData(cpCtxts, size, level, scale)
+	cpData = NewHEData(cpCtxts, size, level, scale)
+	cpData.fingerprint = d.fingerprint
+	cpData.dirty = d.dirty
+	if d.sim != nil {
+		cpData.ciphertexts = nil
+		cpData.sim = make([]*simCiphertext, len(d.sim))
+		for i, ct := range d.sim {
+			cpData.sim[i] = ct.copy()
+		}
+	}
+	return cpData
+}
+
+// pick returns an HEData whose i-th ciphertext is the idx[i]-th ciphertext of d, with the metadata of d.
+// The ciphertexts are shared, not copied.
+func (d *HEData) pick(idx []int) *HEData {
+	cp := *d
+	if d.sim != nil {
+		cp.sim = make([]*simCiphertext, len(idx))
+		for i, j := range idx {
+			cp.sim[i] = d.sim[j]
+		}
+		return &cp
+	}
+	cp.ciphertexts = make([]*rlwe.Ciphertext, len(idx))
+	for i, j := range idx {
+		cp.ciphertexts[i] = d.ciphertexts[j]
+	}
+	return &cp
 }
 
 func (d *HEData) Print() {
 	fmt.Printf("[HEData] size=%d, level=%d, scale=%.3e\n", d.size, d.level, d.scale)
 }
+
+var heDataMagic = [4]byte{'P', 'S', 'H', 'D'}
+
+// heDataMeta is the fixed-size part of the HEData encoding that follows the header.
+type heDataMeta struct {
+	Size         int64
+	Level        int64
+	Scale        float64
+	DirtyPadding bool
+	Ciphertexts  uint32
+}
+
+// heDataMetaV1 is the metadata of version 1, which predates the tracking of the padding slots.
+type heDataMetaV1 struct {
+	Size        int64
+	Level       int64
+	Scale       float64
+	Ciphertexts uint32
+}
+
+// Fingerprint returns the fingerprint of the parameters the ciphertexts were produced with.
+// It is the zero value for HEData that was built directly with NewHEData.
+func (d *HEData) Fingerprint() Fingerprint { return d.fingerprint }
+
+// WriteTo writes the HEData on w: a header with the format version and the parameter
+// fingerprint, the size, level, scale and padding metadata, then every ciphertext.
+// It implements the io.WriterTo interface.
+func (d *HEData) WriteTo(w io.Writer) (n int64, err error) {
+	if d.sim != nil {
+		return 0, fmt.Errorf("simulated HEData cannot be serialized")
+	}
+	if d.fingerprint == (Fingerprint{}) {
+		return 0, fmt.Errorf("HEData has no parameter fingerprint")
+	}
+
+	bw, ok := w.(*bufio.Writer)
+	if !ok {
+		bw = bufio.NewWriter(w)
+	}
+
+	if n, err = writeHeader(bw, heDataMagic, heDataVersion, d.fingerprint); err != nil {
+		return n, err
+	}
+
+	meta := heDataMeta{
+		Size:         int64(d.size),
+		Level:        int64(d.level),
+		Scale:        d.scale,
+		DirtyPadding: d.dirty,
+		Ciphertexts:  uint32(len(d.ciphertexts)),
+	}
+	if err = binary.Write(bw, binary.LittleEndian, &meta); err != nil {
+		return n, fmt.Errorf("write metadata: %w", err)
+	}
+	n += int64(binary.Size(meta))
+
+	for i, ct := range d.ciphertexts {
+		inc, err := ct.WriteTo(bw)
+		n += inc
+		if err != nil {
+			return n, fmt.Errorf("write ciphertext %d: %w", i, err)
+		}
+	}
+	return n, bw.Flush()
+}
+
+// ReadFrom reads an HEData written by WriteTo. It implements the io.ReaderFrom interface.
+// It reads no byte after the end of the HEData, so several of them can be read from one stream.
+func (d *HEData) ReadFrom(r io.Reader) (n int64, err error) {
+	var br buffer.Reader
+	if b, ok := r.(*bufio.Reader); ok {
+		br = b
+	} else {
+		br = &exactReader{r: r}
+	}
+
+	fp, version, n, err := readHeader(br, heDataMagic, heDataVersion)
+	if err != nil {
+		return n, err
+	}
+
+	var meta heDataMeta
+	if version == 1 {
+		// Version 1 does not tell whether the padding slots are clean, so they are assumed dirty.
+		var v1 heDataMetaV1
+		if err = binary.Read(br, binary.LittleEndian, &v1); err != nil {
+			return n, fmt.Errorf("read metadata: %w", err)
+		}
+		n += int64(binary.Size(v1))
+		meta = heDataMeta{Size: v1.Size, Level: v1.Level, Scale: v1.Scale, DirtyPadding: true, Ciphertexts: v1.Ciphertexts}
+	} else {
+		if err = binary.Read(br, binary.LittleEndian, &meta); err != nil {
+			return n, fmt.Errorf("read metadata: %w", err)
+		}
+		n += int64(binary.Size(meta))
+	}
+	if err = meta.validate(); err != nil {
+		return n, fmt.Errorf("invalid metadata: %w", err)
+	}
+
+	// The ciphertexts are appended as they are read, so that the memory follows the bytes actually
+	// received rather than the count announced by the metadata.
+	var ciphertexts []*rlwe.Ciphertext
+	for i := range int(meta.Ciphertexts) {
+		ct := new(rlwe.Ciphertext)
+		inc, err := ct.ReadFrom(br)
+		n += inc
+		if err != nil {
+			return n, fmt.Errorf("read ciphertext %d: %w", i, err)
+		}
+		if i == 0 {
+			if err = meta.validateSlots(ct.Slots()); err != nil {
+				return n, fmt.Errorf("invalid metadata: %w", err)
+			}
+		}
+		if ct.Level() != int(meta.Level) {
+			return n, fmt.Errorf("ciphertext %d is at level %d, expected %d", i, ct.Level(), meta.Level)
+		}
+		ciphertexts = append(ciphertexts, ct)
+	}
+
+	*d = HEData{
+		ciphertexts: ciphertexts,
+		size:        int(meta.Size),
+		level:       int(meta.Level),
+		scale:       meta.Scale,
+		fingerprint: fp,
+		dirty:       meta.DirtyPadding,
+	}
+	return n, nil
+}
+
+// validate checks the metadata on its own, before any ciphertext is read.
+func (m heDataMeta) validate() error {
+	switch {
+	case m.Size <= 0:
+		return fmt.Errorf("size %d is not positive", m.Size)
+	case m.Ciphertexts == 0:
+		return fmt.Errorf("no ciphertexts")
+	case int64(m.Ciphertexts) > m.Size:
+		return fmt.Errorf("%d ciphertexts for %d values", m.Ciphertexts, m.Size)
+	case m.Level < 0:
+		return fmt.Errorf("negative level %d", m.Level)
+	case !(m.Scale > 0) || math.IsInf(m.Scale, 0):
+		return fmt.Errorf("invalid scale %v", m.Scale)
+	}
+	return nil
+}
+
+// validateSlots checks that the size needs exactly the announced number of ciphertexts of the given slots.
+func (m heDataMeta) validateSlots(slots int) error {
+	if need := (m.Size + int64(slots) - 1) / int64(slots); need != int64(m.Ciphertexts) {
+		return fmt.Errorf("%d values need %d ciphertexts of %d slots, got %d", m.Size, need, slots, m.Ciphertexts)
+	}
+	return nil
+}
+
+// exactReader is a buffer.Reader which reads from r only the bytes that are read or peeked.
+// Lattigo wraps any other reader into a bufio.Reader, which would consume the bytes following a ciphertext.
+type exactReader struct {
+	r   io.Reader
+	buf []byte
+}
+
+// exactReaderSize is the largest number of bytes exactReader holds at once.
+const exactReaderSize = 1 << 16
+
+func (e *exactReader) Size() int { return exactReaderSize }
+
+// Read fills p entirely, first from the peeked bytes.
+func (e *exactReader) Read(p []byte) (int, error) {
+	n := copy(p, e.buf)
+	e.buf = e.buf[n:]
+	if n == len(p) {
+		return n, nil
+	}
+	m, err := io.ReadFull(e.r, p[n:])
+	return n + m, err
+}
+
+func (e *exactReader) Peek(n int) ([]byte, error) {
+	if n > exactReaderSize {
+		return nil, bufio.ErrBufferFull
+	}
+	if missing := n - len(e.buf); missing > 0 {
+		more := make([]byte, missing)
+		m, err := io.ReadFull(e.r, more)
+		e.buf = append(e.buf, more[:m]...)
+		if err != nil {
+			return e.buf, err
+		}
+	}
+	return e.buf[:n], nil
+}
+
+func (e *exactReader) Discard(n int) (int, error) {
+	if n <= len(e.buf) {
+		e.buf = e.buf[n:]
+		return n, nil
+	}
+	discarded := len(e.buf)
+	e.buf = nil
+	m, err := io.CopyN(io.Discard, e.r, int64(n-discarded))
+	return discarded + int(m), err
+}
+
+// MarshalBinary encodes the HEData in the format written by WriteTo.
+func (d *HEData) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes an HEData encoded by MarshalBinary.
+func (d *HEData) UnmarshalBinary(p []byte) error {
+	_, err := d.ReadFrom(bytes.NewReader(p))
+	return err
+}
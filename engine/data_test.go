@@ -0,0 +1,129 @@
+package engine_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/hm-choi/pp-stat/config"
+	"github.com/hm-choi/pp-stat/engine"
+)
+
+// newTestClient returns a client with the small insecure Test parameters without bootstrapping,
+// whose keys are generated in a fraction of a second.
+func newTestClient(t *testing.T) *engine.Client {
+	t.Helper()
+	p := config.Test
+	p.IsBTS = false
+	p.Insecure = true
+	c, err := engine.NewClientFromParameters(p)
+	if err != nil {
+		t.Fatalf("NewClientFromParameters: %v", err)
+	}
+	return c
+}
+
+func checkValues(t *testing.T, c *engine.Client, d *engine.HEData, want []float64) {
+	t.Helper()
+	got, err := c.Decrypt(d)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	for i, w := range want {
+		if math.Abs(got[i]-w) > 1e-6 {
+			t.Fatalf("value %d: got %v, want %v", i, got[i], w)
+		}
+	}
+}
+
+func TestHEDataReadFromStream(t *testing.T) {
+	c := newTestClient(t)
+	columns := [][]float64{make([]float64, 700), {1, 2, 3}}
+	for i := range columns[0] {
+		columns[0][i] = float64(i) / 700
+	}
+
+	var buf bytes.Buffer
+	for _, column := range columns {
+		d, err := c.Encrypt(column)
+		if err != nil {
+			t.Fatalf("Encrypt: %v", err)
+		}
+		if _, err = d.WriteTo(&buf); err != nil {
+			t.Fatalf("WriteTo: %v", err)
+		}
+	}
+
+	// A reader without Peek, which Lattigo would otherwise wrap into a bufio.Reader.
+	r := struct{ io.Reader }{&buf}
+	for i, column := range columns {
+		var d engine.HEData
+		if _, err := d.ReadFrom(r); err != nil {
+			t.Fatalf("ReadFrom %d: %v", i, err)
+		}
+		if d.Size() != len(column) {
+			t.Fatalf("size %d: got %d, want %d", i, d.Size(), len(column))
+		}
+		checkValues(t, c, &d, column)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("%d bytes left after the last HEData", buf.Len())
+	}
+}
+
+func TestHEDataReadFromInvalidMetadata(t *testing.T) {
+	c := newTestClient(t)
+	d, err := c.Encrypt([]float64{1, 2, 3})
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	data, err := d.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	// The metadata follows the header of 4+2+32 bytes: size, level, scale, dirty padding and ciphertexts.
+	const meta = 38
+	for name, tamper := range map[string]func(p []byte){
+		"zero size":            func(p []byte) { binary.LittleEndian.PutUint64(p[meta:], 0) },
+		"negative level":       func(p []byte) { binary.LittleEndian.PutUint64(p[meta+8:], math.MaxUint64) },
+		"invalid scale":        func(p []byte) { binary.LittleEndian.PutUint64(p[meta+16:], math.Float64bits(math.NaN())) },
+		"too many ciphertexts": func(p []byte) { binary.LittleEndian.PutUint32(p[meta+25:], math.MaxUint32) },
+		"size beyond slots":    func(p []byte) { binary.LittleEndian.PutUint64(p[meta:], 1<<20) },
+	} {
+		p := bytes.Clone(data)
+		tamper(p)
+		if err := new(engine.HEData).UnmarshalBinary(p); err == nil {
+			t.Errorf("%s: UnmarshalBinary succeeded", name)
+		}
+	}
+}
+
+func TestHEDataReadVersion1(t *testing.T) {
+	c := newTestClient(t)
+	column := []float64{1, 2, 3}
+	d, err := c.Encrypt(column)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	data, err := d.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	// Version 1 has no dirty padding byte between the scale and the number of ciphertexts.
+	const meta = 38
+	v1 := append(bytes.Clone(data[:meta+24]), data[meta+25:]...)
+	binary.LittleEndian.PutUint16(v1[4:], 1)
+
+	var got engine.HEData
+	if err := got.UnmarshalBinary(v1); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	if !got.DirtyPadding() {
+		t.Errorf("version 1 HEData must be assumed to have dirty padding")
+	}
+	checkValues(t, c, &got, column)
+}
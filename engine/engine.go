@@ -3,64 +3,81 @@ package engine
 import (
 	"fmt"
 
+	"github.com/hm-choi/pp-stat/config"
 	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
-	"github.com/tuneinsight/lattigo/v6/core/rlwe"
 	"github.com/tuneinsight/lattigo/v6/ring"
 	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
 	"github.com/tuneinsight/lattigo/v6/utils"
 )
 
+// HEEngine bundles a Client and a Server sharing the same parameters, for the
+// setting where the data owner also runs the computation.
+// The statistical methods are those of the embedded Server.
 type HEEngine struct {
-	params    ckks.Parameters
-	Sk        *rlwe.SecretKey
-	Pk        *rlwe.PublicKey
-	Rlk       *rlwe.RelinearizationKey
-	Evk       *bootstrapping.EvaluationKeys
-	Encryptor *rlwe.Encryptor
-	Decryptor *rlwe.Decryptor
-	evaluator *ckks.Evaluator
-	Encoder   *ckks.Encoder
-	BTS       *bootstrapping.Evaluator
-	Slots     int
-	IsBTS     bool
+	*Client
+	*Server
 }
 
-func (e *HEEngine) Evaluator() *ckks.Evaluator { return e.evaluator }
-func (e *HEEngine) Params() ckks.Parameters    { return e.params }
+func (e *HEEngine) Params() ckks.Parameters { return e.Server.params }
 
-func GetParam(LogN int, LEVEL int, SCALE int) ckks.Parameters {
+// Fingerprint returns the fingerprint of the engine parameters.
+func (e *HEEngine) Fingerprint() Fingerprint { return e.Server.fingerprint }
+
+// checkParams explains with config.Validate why params cannot be used by pp-stat.
+func checkParams(isBTS bool, params ckks.Parameters) error {
+	return config.Validate(params.LogN(), params.MaxLevel(), params.LogDefaultScale(), isBTS)
+}
+
+func GetParam(LogN int, LEVEL int, SCALE int) (ckks.Parameters, error) {
+	if err := config.Validate(LogN, LEVEL, SCALE, false); err != nil {
+		return ckks.Parameters{}, err
+	}
 	LogQ := make([]int, LEVEL+1)
-	LogQ[0] = 60
+	LogQ[0] = config.FirstModulusSize
 	for i := range LEVEL {
 		LogQ[i+1] = SCALE
 	}
 
-	params, _ := ckks.NewParametersFromLiteral(
+	params, err := ckks.NewParametersFromLiteral(
 		ckks.ParametersLiteral{
 			LogN:            LogN,                  // log2(ring degree)
 			LogQ:            LogQ,                  // log2(primes Q) (ciphertext modulus)
 			LogP:            []int{61, 61, 61, 61}, // log2(primes P) (auxiliary modulus)
 			LogDefaultScale: SCALE,                 // log2(scale)
 		})
-	return params
+	if err != nil {
+		return ckks.Parameters{}, fmt.Errorf("ckks parameters: %w", err)
+	}
+	return params, nil
 }
 
-func GetBSParam(LogN int, LEVEL int, SCALE int) (ckks.Parameters, bootstrapping.Parameters) {
+// bsDefaultScale is the log2 of the default scale of the parameters of GetBSParam.
+const bsDefaultScale = 40
+
+// GetBSParam returns the parameters with bootstrapping of LogN and LEVEL moduli of SCALE bits.
+// Their default scale is fixed to 2^40, so SCALE must be 40.
+func GetBSParam(LogN int, LEVEL int, SCALE int) (ckks.Parameters, bootstrapping.Parameters, error) {
+	if SCALE != bsDefaultScale {
+		return ckks.Parameters{}, bootstrapping.Parameters{}, fmt.Errorf("invalid parameters: SCALE=%d, the default scale of GetBSParam is fixed to %d bits", SCALE, bsDefaultScale)
+	}
+	if err := config.Validate(LogN, LEVEL, SCALE, true); err != nil {
+		return ckks.Parameters{}, bootstrapping.Parameters{}, err
+	}
 	logQ := make([]int, LEVEL+1)
-	logQ[0] = 60
+	logQ[0] = config.FirstModulusSize
 	for idx := range LEVEL {
 		logQ[idx+1] = SCALE
 	}
 	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
-		LogN:            LogN,          // Log2 of the ring degree
-		LogQ:            logQ,          // Log2 of the ciphertext prime moduli
-		LogP:            []int{61, 61}, // Log2 of the key-switch auxiliary prime moduli
-		LogDefaultScale: 40,            // Log2 of the scale
+		LogN:            LogN,           // Log2 of the ring degree
+		LogQ:            logQ,           // Log2 of the ciphertext prime moduli
+		LogP:            []int{61, 61},  // Log2 of the key-switch auxiliary prime moduli
+		LogDefaultScale: bsDefaultScale, // Log2 of the scale
 		Xs:              ring.Ternary{H: 192},
 	})
 
 	if err != nil {
-		panic(err)
+		return ckks.Parameters{}, bootstrapping.Parameters{}, fmt.Errorf("ckks parameters: %w", err)
 	}
 
 	btpParametersLit := bootstrapping.ParametersLiteral{
@@ -71,130 +88,70 @@ func GetBSParam(LogN int, LEVEL int, SCALE int) (ckks.Parameters, bootstrapping.
 
 	btpParams, err := bootstrapping.NewParametersFromLiteral(params, btpParametersLit)
 	if err != nil {
-		panic(err)
+		return ckks.Parameters{}, bootstrapping.Parameters{}, fmt.Errorf("bootstrapping parameters: %w", err)
 	}
 
-	return params, btpParams
+	return params, btpParams, nil
 }
 
-func NewHEEngine(isBTS bool, params ckks.Parameters, btpParams bootstrapping.Parameters) *HEEngine {
-	kgen := rlwe.NewKeyGenerator(params)
-	sk, pk := kgen.GenKeyPairNew()
-	rlk := kgen.GenRelinearizationKeyNew(sk)
-
-	galEls := []uint64{params.GaloisElementForComplexConjugation()}
-	for rot := 1; rot < params.MaxSlots(); rot *= 2 {
-		galEls = append(galEls, params.GaloisElement(rot))
-	}
-
-	ecd := ckks.NewEncoder(params)
-	dec := rlwe.NewDecryptor(params, sk)
-	enc := rlwe.NewEncryptor(params, pk)
-
-	var eval *ckks.Evaluator
-
-	var bts *bootstrapping.Evaluator
-
-	if isBTS {
-		btsEvk, _, _ := btpParams.GenEvaluationKeys(sk)
-		eval = ckks.NewEvaluator(params, btsEvk)
-		eval = eval.WithKey(rlwe.NewMemEvaluationKeySet(rlk, kgen.GenGaloisKeysNew(galEls, sk)...))
-		bts, _ = bootstrapping.NewEvaluator(btpParams, btsEvk)
-	} else {
-		evk := rlwe.NewMemEvaluationKeySet(rlk)
-		eval = ckks.NewEvaluator(params, evk)
-		eval = eval.WithKey(rlwe.NewMemEvaluationKeySet(rlk, kgen.GenGaloisKeysNew(galEls, sk)...))
+// NewHEEngine generates the keys of an HEEngine for the given parameters.
+// Parameters estimated below 128-bit security are refused, see NewHEEngineFromParameters to use them anyway.
+func NewHEEngine(isBTS bool, params ckks.Parameters, btpParams bootstrapping.Parameters) (*HEEngine, error) {
+	client, err := NewClient(isBTS, params, btpParams)
+	if err != nil {
+		return nil, err
 	}
+	return newHEEngine(client)
+}
 
-	return &HEEngine{
-		params:    params,
-		Sk:        sk,
-		Pk:        pk,
-		Rlk:       rlk,
-		Evk:       nil,
-		Encryptor: enc,
-		Decryptor: dec,
-		evaluator: eval,
-		Encoder:   ecd,
-		BTS:       bts,
-		Slots:     params.MaxSlots(),
-		IsBTS:     isBTS,
+// NewHEEngineFromParameters generates the keys of an HEEngine for the named parameter set p, e.g. config.Sec128Small.
+// Insecure parameters such as config.Test are only accepted if p.Insecure is set.
+func NewHEEngineFromParameters(p config.Parameters) (*HEEngine, error) {
+	client, err := NewClientFromParameters(p)
+	if err != nil {
+		return nil, err
 	}
+	return newHEEngine(client)
 }
 
-func (e *HEEngine) Encrypt(input []float64) (ctxt *HEData, err error) {
-	if len(input) == 0 {
-		return nil, fmt.Errorf("input data size is zero: %w", err)
+// newHEEngine generates the evaluation keys of client and builds the Server using them.
+func newHEEngine(client *Client) (*HEEngine, error) {
+	isBTS, params, btpParams := client.isBTS, client.params, client.btpParams
+	evk, err := client.GenEvaluationKeys()
+	if err != nil {
+		return nil, fmt.Errorf("generate evaluation keys: %w", err)
 	}
-
-	dataSize := len(input)
-
-	ctxtNum := ((len(input) + e.Slots - 1) / e.Slots)
-
-	ciphertexts := make([]*rlwe.Ciphertext, ctxtNum)
-	for i := range ctxtNum {
-		start := i * e.Slots
-		end := start + e.Slots
-		if end > len(input) {
-			end = len(input)
-		}
-
-		pt := ckks.NewPlaintext(e.params, e.params.MaxLevel())
-		if err = e.Encoder.Encode(input[start:end], pt); err != nil {
-			return nil, fmt.Errorf("encoding failed: %w", err)
-		}
-		ctxt, err := e.Encryptor.EncryptNew(pt)
-		if err != nil {
-			return nil, fmt.Errorf("encryption failed: %w", err)
-		}
-		ciphertexts[i] = ctxt
+	server, err := NewServer(isBTS, params, btpParams, evk)
+	if err != nil {
+		return nil, err
 	}
-	heData := NewHEData(ciphertexts, dataSize, e.params.MaxLevel(), 60.0)
-	return heData, nil
+	return &HEEngine{Client: client, Server: server}, nil
 }
 
-func (e *HEEngine) Decrypt(ctxt *HEData) (output []float64, err error) {
-	output = []float64{}
-	ctxts := ctxt.Ciphertexts()
-	for i := range len(ctxts) {
-		tmpSlice := make([]float64, e.params.MaxSlots())
-		if err = e.Encoder.Decode(e.Decryptor.DecryptNew(ctxts[i]), tmpSlice); err != nil {
-			return nil, fmt.Errorf("decoding failed: %w", err)
-		}
-		output = append(output, tmpSlice...)
-	}
-	return output[:ctxt.Size()], nil
+// galoisElements returns the Galois elements of the keys generated for a Server: those of
+// requiredGaloisElements followed by those of rightRotationElements.
+func galoisElements(params ckks.Parameters) []uint64 {
+	return append(requiredGaloisElements(params), rightRotationElements(params)...)
 }
 
-func (e *HEEngine) DecryptComplex(ctxt *HEData) (output []complex128, err error) {
-	output = []complex128{}
-	ctxts := ctxt.Ciphertexts()
-	for i := range len(ctxts) {
-		tmpSlice := make([]complex128, e.params.MaxSlots())
-		if err = e.Encoder.Decode(e.Decryptor.DecryptNew(ctxts[i]), tmpSlice); err != nil {
-			return nil, fmt.Errorf("decoding failed: %w", err)
-		}
-		output = append(output, tmpSlice...)
+// requiredGaloisElements returns the Galois elements of the complex conjugation and of every
+// power-of-two rotation to the left, which are the ones used by Sum and the polynomial evaluators.
+func requiredGaloisElements(params ckks.Parameters) []uint64 {
+	galEls := []uint64{params.GaloisElementForComplexConjugation()}
+	for rot := 1; rot < params.MaxSlots(); rot *= 2 {
+		galEls = append(galEls, params.GaloisElement(rot))
 	}
-	return output, nil
+	return galEls
 }
-func (e *HEEngine) DoBootstrap(ctxt *HEData, level int) (*HEData, error) {
-	if !e.IsBTS {
-		return nil, fmt.Errorf("The parameter does not support bootstrapping.")
-	}
-	if ctxt.Ciphertexts()[0].Level() < level {
-		ctxtNum := len(ctxt.Ciphertexts())
-		btsCtxts := make([]*rlwe.Ciphertext, ctxtNum)
-		for i := 0; i < ctxtNum; i++ {
-			ct := ctxt.Ciphertexts()[i].CopyNew()
-			ct.Scale = e.params.DefaultScale().Mul(rlwe.NewScale(2))
-			conj, _ := e.evaluator.ConjugateNew(ct)
-			ct, _ = e.evaluator.AddNew(conj, ct)
-			ct, _ = e.BTS.Bootstrap(ct)
-			btsCtxts[i] = ct
-		}
-		return NewHEData(btsCtxts, ctxt.Size(), btsCtxts[0].Level(), ctxt.Scale()), nil
-	} else {
-		return ctxt, nil
+
+// rightRotationElements returns the Galois elements of every power-of-two rotation to the right,
+// which only the rotations by a negative offset of the sorting network use. Keys exported before
+// they were generated lack them: such a Server loads, and fails at the first rotation to the right.
+// The rotation by half the slots is the same in both directions and is left out.
+func rightRotationElements(params ckks.Parameters) []uint64 {
+	var galEls []uint64
+	for rot := 1; rot < params.MaxSlots()/2; rot *= 2 {
+		galEls = append(galEls, params.GaloisElement(-rot))
 	}
+	return galEls
 }
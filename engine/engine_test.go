@@ -0,0 +1,20 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/hm-choi/pp-stat/engine"
+)
+
+func TestGetBSParamScale(t *testing.T) {
+	params, _, err := engine.GetBSParam(10, 11, 40)
+	if err != nil {
+		t.Fatalf("GetBSParam with SCALE=40: %v", err)
+	}
+	if params.LogDefaultScale() != 40 {
+		t.Errorf("default scale of 2^%d, want 2^40", params.LogDefaultScale())
+	}
+	if _, _, err = engine.GetBSParam(10, 11, 30); err == nil {
+		t.Errorf("GetBSParam accepted SCALE=30, which its default scale of 2^40 ignores")
+	}
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+// keyFormatVersion is the version of the key and parameter files written by this package, and
+// heDataVersion the one of the HEData encoding. Each must be increased whenever the layout of the
+// objects it covers changes; readers keep accepting the earlier versions they know how to decode.
+const (
+	keyFormatVersion uint16 = 1
+	heDataVersion    uint16 = 2
+)
+
+// Fingerprint identifies the ckks.Parameters a serialized object belongs to.
+// It is the SHA-256 digest of the binary encoding of the parameters.
+type Fingerprint [sha256.Size]byte
+
+// ParamsFingerprint returns the Fingerprint of the given parameters.
+func ParamsFingerprint(params ckks.Parameters) (Fingerprint, error) {
+	data, err := params.MarshalBinary()
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("marshal parameters: %w", err)
+	}
+	return sha256.Sum256(data), nil
+}
+
+// String returns the first bytes of the fingerprint in hexadecimal, which is enough to tell parameter sets apart in error messages.
+func (f Fingerprint) String() string {
+	return fmt.Sprintf("%x", f[:8])
+}
+
+// header is the preamble written in front of every serialized object.
+type header struct {
+	Magic       [4]byte
+	Version     uint16
+	Fingerprint Fingerprint
+}
+
+func writeHeader(w io.Writer, magic [4]byte, version uint16, fp Fingerprint) (int64, error) {
+	hdr := header{Magic: magic, Version: version, Fingerprint: fp}
+	if err := binary.Write(w, binary.LittleEndian, &hdr); err != nil {
+		return 0, fmt.Errorf("write header: %w", err)
+	}
+	return int64(binary.Size(hdr)), nil
+}
+
+// readHeader reads a header and checks its magic and that its version is between 1 and version.
+// The fingerprint is returned to the caller, which decides whether it must match a known parameter set,
+// with the version, which tells the caller how to decode what follows.
+func readHeader(r io.Reader, magic [4]byte, version uint16) (Fingerprint, uint16, int64, error) {
+	var hdr header
+	if err := binary.Read(r, binary.LittleEndian, &hdr); err != nil {
+		return Fingerprint{}, 0, 0, fmt.Errorf("read header: %w", err)
+	}
+	n := int64(binary.Size(hdr))
+	if hdr.Magic != magic {
+		return Fingerprint{}, 0, n, fmt.Errorf("invalid magic %q, expected %q", hdr.Magic[:], magic[:])
+	}
+	if hdr.Version < 1 || hdr.Version > version {
+		return Fingerprint{}, 0, n, fmt.Errorf("unsupported format version %d, expected at most %d", hdr.Version, version)
+	}
+	return hdr.Fingerprint, hdr.Version, n, nil
+}
+
+// writeBlob writes a length-prefixed byte slice.
+func writeBlob(w io.Writer, p []byte) (int64, error) {
+	if err := binary.Write(w, binary.LittleEndian, uint64(len(p))); err != nil {
+		return 0, err
+	}
+	n, err := w.Write(p)
+	return int64(n) + 8, err
+}
+
+// maxBlobSize bounds the length of a blob read by readBlob. Blobs hold parameter encodings, which
+// take a few kilobytes, so a larger length comes from a corrupt or hostile input.
+const maxBlobSize = 1 << 24
+
+// readBlob reads a byte slice written by writeBlob.
+func readBlob(r io.Reader) ([]byte, int64, error) {
+	var size uint64
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return nil, 0, err
+	}
+	if size > maxBlobSize {
+		return nil, 8, fmt.Errorf("blob length %d exceeds the maximum of %d bytes", size, maxBlobSize)
+	}
+	p := make([]byte, size)
+	n, err := io.ReadFull(r, p)
+	return p, int64(n) + 8, err
+}
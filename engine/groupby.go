@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hm-choi/pp-stat/utils"
+)
+
+// EncryptCategorical one-hot encodes a categorical column with utils.OneHot and encrypts the
+// indicator column of every category. It returns the sorted categories and, in the same order,
+// their encrypted 0/1 columns, which are the groups of GroupBySum and GroupByMean.
+func (c *Client) EncryptCategorical(values []string) ([]string, []*HEData, error) {
+	categories, columns := utils.OneHot(values)
+	groups := make([]*HEData, len(columns))
+	for k, column := range columns {
+		group, err := c.Encrypt(column)
+		if err != nil {
+			return nil, nil, fmt.Errorf("encrypt category %q: %w", categories[k], err)
+		}
+		groups[k] = group
+	}
+	return categories, groups, nil
+}
+
+// GroupBySum returns, for every group, the sum of the rows of ct in that group, replicated in every
+// slot like Sum. groups are encrypted 0/1 columns, like those of EncryptCategorical.
+func (e *Server) GroupBySum(ct *HEData, groups []*HEData) ([]*HEData, error) {
+	return e.GroupBySumCtx(context.Background(), ct, groups)
+}
+
+// GroupBySumCtx is GroupBySum returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps. Its progress is reported to e.Progress.
+func (e *Server) GroupBySumCtx(ctx context.Context, ct *HEData, groups []*HEData) ([]*HEData, error) {
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("no groups")
+	}
+	sums := make([]*HEData, len(groups))
+	for k, group := range groups {
+		sum, err := e.SumIfCtx(ctx, ct, Where(group))
+		if err != nil {
+			return nil, fmt.Errorf("group %d: %w", k, err)
+		}
+		sums[k] = sum
+	}
+	return sums, nil
+}
+
+// GroupByMean returns, for every group, the mean of the rows of ct in that group, replicated in every
+// slot like Sum. groups are encrypted 0/1 columns, like those of EncryptCategorical, and each of them
+// must select at least one row; the division is the one of MeanIf.
+func (e *Server) GroupByMean(ct *HEData, groups []*HEData) ([]*HEData, error) {
+	return e.GroupByMeanCtx(context.Background(), ct, groups)
+}
+
+// GroupByMeanCtx is GroupByMean returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps or Newton iterations. Its progress is reported to e.Progress.
+func (e *Server) GroupByMeanCtx(ctx context.Context, ct *HEData, groups []*HEData) ([]*HEData, error) {
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("no groups")
+	}
+	means := make([]*HEData, len(groups))
+	for k, group := range groups {
+		mean, err := e.MeanIfCtx(ctx, ct, Where(group))
+		if err != nil {
+			return nil, fmt.Errorf("group %d: %w", k, err)
+		}
+		means[k] = mean
+	}
+	return means, nil
+}
@@ -0,0 +1,51 @@
+package engine_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestSimGroupBy(t *testing.T) {
+	e := newSimEngine(t, true)
+	regions := []string{"north", "south", "east"}
+	values := uniform(300, 10, 10)
+	labels := make([]string, len(values))
+	sums := map[string]float64{}
+	counts := map[string]float64{}
+	for i, v := range values {
+		labels[i] = regions[i%len(regions)]
+		sums[labels[i]] += v
+		counts[labels[i]]++
+	}
+	ct, err := e.Encrypt(values)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	categories, groups, err := e.EncryptCategorical(labels)
+	if err != nil {
+		t.Fatalf("EncryptCategorical: %v", err)
+	}
+	if want := []string{"east", "north", "south"}; !slices.Equal(categories, want) {
+		t.Fatalf("categories = %v, want %v", categories, want)
+	}
+
+	gotSums, err := e.GroupBySum(ct, groups)
+	if err != nil {
+		t.Fatalf("GroupBySum: %v", err)
+	}
+	gotMeans, err := e.GroupByMean(ct, groups)
+	if err != nil {
+		t.Fatalf("GroupByMean: %v", err)
+	}
+	for k, category := range categories {
+		sum := decryptSim(t, e, gotSums[k], nil)[0]
+		if want := sums[category]; math.Abs(sum-want)/want > 1e-3 {
+			t.Errorf("GroupBySum[%s] = %v, want %v", category, sum, want)
+		}
+		mean := decryptSim(t, e, gotMeans[k], nil)[0]
+		if want := sums[category] / counts[category]; math.Abs(mean-want)/want > 1e-3 {
+			t.Errorf("GroupByMean[%s] = %v, want %v", category, mean, want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// Histogram returns the encrypted vector of the bin counts of ct, of size len(edges)-1, whose k-th slot is
+// the number of values of ct in the bin (edges[k], edges[k+1]). edges must be increasing; the values outside
+// of (edges[0], edges[len(edges)-1]) are not counted, and a value equal to an edge counts for one half in
+// each of the bins around it, as step(0) = 0.5.
+//
+// The values of ct and the edges must lie in [-B, B], so that their difference divided by 2B lies in [-1, 1].
+// The number of values larger than every edge is the Sum of Step(x - edge), and the bin counts are the
+// differences of two consecutive such numbers. Each edge evaluates a step function. Bootstrapping is required.
+func (e *Server) Histogram(ct *HEData, edges []float64, B float64) (*HEData, error) {
+	return e.HistogramCtx(context.Background(), ct, edges, B)
+}
+
+// HistogramCtx is Histogram returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps. Its progress is reported to e.Progress.
+func (e *Server) HistogramCtx(ctx context.Context, ct *HEData, edges []float64, B float64) (*HEData, error) {
+	slots := e.params.MaxSlots()
+	bins := len(edges) - 1
+	if bins < 1 {
+		return nil, fmt.Errorf("a histogram needs at least two edges, got %d", len(edges))
+	}
+	if bins > slots {
+		return nil, fmt.Errorf("%d bins do not fit in the %d slots of a ciphertext", bins, slots)
+	}
+	for k, edge := range edges {
+		if math.IsNaN(edge) || math.Abs(edge) > B {
+			return nil, fmt.Errorf("edge %v is not in [-B, B]", edge)
+		}
+		if k > 0 && edge <= edges[k-1] {
+			return nil, fmt.Errorf("edges are not increasing: %v after %v", edge, edges[k-1])
+		}
+	}
+
+	var hist *HEData
+	for k, edge := range edges {
+		// Step 1: Number of values larger than the edge, replicated in every slot
+		above, err := e.GreaterThanConstCtx(ctx, ct, edge, B)
+		if err != nil {
+			return nil, fmt.Errorf("compare with edge %v: %w", edge, err)
+		}
+		if above, err = e.doBootstrap(ctx, above, 2); err != nil {
+			return nil, fmt.Errorf("bootstrap step of edge %v: %w", edge, err)
+		}
+		count, err := e.Sum(above)
+		if err != nil {
+			return nil, fmt.Errorf("sum of step of edge %v: %w", edge, err)
+		}
+
+		// Step 2: Add it to the count of the bin above the edge and subtract it from the one below
+		weights := make([]float64, slots)
+		if k < bins {
+			weights[k] = 1
+		}
+		if k > 0 {
+			weights[k-1] = -1
+		}
+		count = e.result(count.pick([]int{0}), bins, false)
+		term, err := e.multVec(count, [][]float64{weights}, false)
+		if err != nil {
+			return nil, fmt.Errorf("place count of edge %v: %w", edge, err)
+		}
+		if hist == nil {
+			hist = term
+		} else if hist, err = e.Add(hist, term); err != nil {
+			return nil, fmt.Errorf("add count of edge %v: %w", edge, err)
+		}
+	}
+	return hist, nil
+}
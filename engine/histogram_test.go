@@ -0,0 +1,33 @@
+package engine_test
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimHistogram(t *testing.T) {
+	e := newSimEngine(t, true)
+	const B = 10.0
+	values := uniform(200, B, 11)
+	edges := []float64{0.5, 2.5, 5.5, 9.5}
+	ct, err := e.Encrypt(values)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	want := make([]float64, len(edges)-1)
+	for _, v := range values {
+		for k := range want {
+			if edges[k] < v && v < edges[k+1] {
+				want[k]++
+			}
+		}
+	}
+	d, err := e.Histogram(ct, edges, B)
+	got := decryptSim(t, e, d, err)
+	for k := range want {
+		if math.Abs(got[k]-want[k]) > 1e-2 {
+			t.Errorf("bin (%v, %v): got %v, want %v", edges[k], edges[k+1], got[k], want[k])
+		}
+	}
+}
@@ -1,14 +1,12 @@
 package engine
 
 import (
+	"context"
 	"fmt"
 	"math"
-
-	"github.com/tuneinsight/lattigo/v6/circuits/ckks/polynomial"
-	"github.com/tuneinsight/lattigo/v6/core/rlwe"
 )
 
-func (e *HEEngine) ChebyshevInvSqrt(ct *HEData, mode int, B float64) (*HEData, error) {
+func (e *Server) ChebyshevInvSqrt(ct *HEData, mode int, B float64) (*HEData, error) {
 	cpData := ct.CopyData()
 	d := 9.0
 
@@ -16,7 +14,10 @@ func (e *HEEngine) ChebyshevInvSqrt(ct *HEData, mode int, B float64) (*HEData, e
 
 	switch mode {
 	case 1:
-		cpData, _ = e.MultConst(cpData, 2.0/B)
+		var err error
+		if cpData, err = e.MultConst(cpData, 2.0/B); err != nil {
+			return nil, err
+		}
 		F = func(x float64) (y float64) {
 			if x > -1.0 {
 				return 1 / math.Sqrt(B/2) / (math.Sqrt(x + 1.0))
@@ -49,89 +50,98 @@ func (e *HEEngine) ChebyshevInvSqrt(ct *HEData, mode int, B float64) (*HEData, e
 		return nil, err
 	}
 	gcbsp := GetChebyshevPoly(1.0, int(math.Pow(2, float64(d))-2), F)
-	poly := polynomial.NewPolynomial(gcbsp)
-	polyEval := polynomial.NewEvaluator(e.params, e.Evaluator())
-
-	scaledCtxts := scaled_ct.Ciphertexts()
-	invCtxts := []*rlwe.Ciphertext{}
-	targetScale := e.params.DefaultScale().Div(rlwe.NewScale(2))
-	for i := 0; i < len(scaledCtxts); i++ {
-		// tmpList1 := make([]float64, e.params.MaxSlots())
-		// tmpList2 := make([]float64, e.params.MaxSlots())
-		// if scaled_ct.Size() < e.params.MaxSlots() {
-		// 	for j := scaled_ct.Size(); j < e.params.MaxSlots(); j++ {
-		// 		tmpList1[j] = 1
-		// 		tmpList2[j] = 1 / math.Sqrt(B)
-		// 	}
-		// 	e.evaluator.Add(scaledCtxts[i], tmpList1, scaledCtxts[i])
-		// }
-		p2, _ := polyEval.Evaluate(scaledCtxts[i], poly, targetScale)
-		p2.Scale = p2.Scale.Mul(rlwe.NewScale(2))
-		conj, _ := e.evaluator.ConjugateNew(p2)
-		e.evaluator.Add(p2, conj, p2)
-		p2.Scale = scaledCtxts[i].Scale
-		invCtxts = append(invCtxts, p2)
-		// if scaled_ct.Size() < e.params.MaxSlots() {
-		// 	e.evaluator.Sub(scaledCtxts[i], tmpList1, scaledCtxts[i])
-		// 	e.evaluator.Sub(invCtxts[i], tmpList2, invCtxts[i])
-		// }
-	}
-	return NewHEData(invCtxts, ct.Size(), invCtxts[0].Level(), ct.Scale()), nil
+	return e.evalPoly(scaled_ct, gcbsp)
 }
 
-func (e *HEEngine) HENewtonInv(ct, init *HEData, B float64, iter, mode int) (*HEData, error) {
+func (e *Server) HENewtonInv(ct, init *HEData, B float64, iter, mode int) (*HEData, error) {
+	return e.newtonInv(context.Background(), ct, init, B, iter, mode)
+}
+
+// newtonInv is HENewtonInv checking ctx and reporting a StageNewton event after every iteration.
+func (e *Server) newtonInv(ctx context.Context, ct, init *HEData, B float64, iter, mode int) (*HEData, error) {
 	N := 1.0
 	x, y := ct.CopyData(), init.CopyData()
+	var err error
 	switch mode {
 	case 1:
-		x, _ = e.MultConst(x, B)
+		x, err = e.MultConst(x, B)
 	case 2:
 		N = 2
-		x, _ = e.MultConst(x, 1.0/N)
+		x, err = e.MultConst(x, 1.0/N)
 	case 3:
 		N = 2
-		x, _ = e.MultConst(x, B/N)
+		x, err = e.MultConst(x, B/N)
+	}
+	if err != nil {
+		return nil, err
 	}
-	for _ = range iter {
+	for i := range iter {
 		if e.IsBTS {
-			y, _ = e.DoBootstrap(y, 3)
+			if y, err = e.doBootstrap(ctx, y, 3); err != nil {
+				return nil, err
+			}
 		}
 
-		tmp_a, _ := e.MultConst(y, float64((N+1))/float64(N))
-		tmp_b, _ := e.Mult(x, y)
+		tmp_a, err := e.MultConst(y, float64((N+1))/float64(N))
+		if err != nil {
+			return nil, err
+		}
+		tmp_b, err := e.Mult(x, y)
+		if err != nil {
+			return nil, err
+		}
 
 		if N == 2.0 {
-			y, _ = e.Mult(y, y)
+			if y, err = e.Mult(y, y); err != nil {
+				return nil, err
+			}
+		}
+		if tmp_b, err = e.Mult(tmp_b, y); err != nil {
+			return nil, err
+		}
+		if y, err = e.Sub(tmp_a, tmp_b); err != nil {
+			return nil, err
+		}
+
+		if err := e.checkpoint(ctx, ProgressEvent{Stage: StageNewton, Iteration: i + 1, Level: y.Level()}); err != nil {
+			return nil, err
 		}
-		tmp_b, _ = e.Mult(tmp_b, y)
-		y, _ = e.Sub(tmp_a, tmp_b)
 	}
 	return y, nil
 }
 
-func (e *HEEngine) CryptoInvSqrt(ct *HEData, B float64) (*HEData, error) {
+func (e *Server) CryptoInvSqrt(ct *HEData, B float64) (*HEData, error) {
+	return e.cryptoInvSqrt(context.Background(), ct, B)
+}
+
+// cryptoInvSqrt is CryptoInvSqrt checking ctx and reporting its bootstrap and Newton iterations.
+func (e *Server) cryptoInvSqrt(ctx context.Context, ct *HEData, B float64) (*HEData, error) {
 	y, err := e.ChebyshevInvSqrt(ct, 1, B)
 	if err != nil {
 		return y, err
 	}
 	if e.IsBTS {
-		y, err = e.DoBootstrap(y, 3)
+		if y, err = e.doBootstrap(ctx, y, 3); err != nil {
+			return nil, err
+		}
 	}
-	return e.HENewtonInv(ct, y, B, 6, 2)
+	return e.newtonInv(ctx, ct, y, B, 6, 2)
 }
 
-func (e *HEEngine) CryptoInv(ct *HEData) (*HEData, error) {
+func (e *Server) CryptoInv(ct *HEData) (*HEData, error) {
 	y0, err := e.ChebyshevInvSqrt(ct, 2, 1.0)
 	if err != nil {
 		return y0, err
 	}
 
-	y0, err = e.Mult(y0, y0)
+	if y0, err = e.Mult(y0, y0); err != nil {
+		return nil, err
+	}
 	return e.HENewtonInv(ct, y0, 1.0, 4, 1)
 
 }
 
-func (e *HEEngine) CryptoSqrt(ct *HEData, types int, B float64) (*HEData, error) {
+func (e *Server) CryptoSqrt(ct *HEData, types int, B float64) (*HEData, error) {
 	d := 9
 	F3 := func(x float64) (y float64) {
 		if x > -1.0 {
@@ -143,7 +153,10 @@ func (e *HEEngine) CryptoSqrt(ct *HEData, types int, B float64) (*HEData, error)
 
 	x := ct.CopyData()
 	if types == 1 {
-		x, _ = e.MultConst(x, 2.0/B)
+		var err error
+		if x, err = e.MultConst(x, 2.0/B); err != nil {
+			return nil, err
+		}
 	} else if types == 2 {
 		F3 = func(x float64) (y float64) {
 			if x > -1.0 {
@@ -154,20 +167,10 @@ func (e *HEEngine) CryptoSqrt(ct *HEData, types int, B float64) (*HEData, error)
 		}
 	}
 
-	scaled_ct, _ := e.SubConst(x, 1)
-	gcbsp := GetChebyshevPoly(1.0, int(math.Pow(2, float64(d))-2), F3)
-	poly := polynomial.NewPolynomial(gcbsp)
-	polyEval := polynomial.NewEvaluator(e.params, e.evaluator)
-
-	ctxts := make([]*rlwe.Ciphertext, len(scaled_ct.Ciphertexts()))
-	for i := 0; i < len(ctxts); i++ {
-		p2, _ := polyEval.Evaluate(scaled_ct.Ciphertexts()[i], poly, e.params.DefaultScale().Div(rlwe.NewScale(2)))
-		p2.Scale = p2.Scale.Mul(rlwe.NewScale(2))
-		conj, _ := e.evaluator.ConjugateNew(p2)
-		e.evaluator.Add(p2, conj, p2)
-		p2.Scale = scaled_ct.Ciphertexts()[i].Scale
-		ctxts[i] = p2
+	scaled_ct, err := e.SubConst(x, 1)
+	if err != nil {
+		return nil, err
 	}
-
-	return NewHEData(ctxts, ct.Size(), ctxts[0].Level(), ct.Scale()), nil
+	gcbsp := GetChebyshevPoly(1.0, int(math.Pow(2, float64(d))-2), F3)
+	return e.evalPoly(scaled_ct, gcbsp)
 }
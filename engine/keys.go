@@ -0,0 +1,378 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hm-choi/pp-stat/config"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+// File names used by ExportKeys and the loaders inside the key directory.
+const (
+	ParamsFile    = "params.bin"
+	SecretKeyFile = "sk.bin"
+	PublicKeyFile = "pk.bin"
+	EvalKeyFile   = "evk.bin"
+	BtsKeyFile    = "btskey.bin"
+)
+
+var (
+	paramsMagic    = [4]byte{'P', 'S', 'P', 'R'}
+	secretKeyMagic = [4]byte{'P', 'S', 'S', 'K'}
+	publicKeyMagic = [4]byte{'P', 'S', 'P', 'K'}
+	evalKeyMagic   = [4]byte{'P', 'S', 'E', 'K'}
+	btsKeyMagic    = [4]byte{'P', 'S', 'B', 'K'}
+)
+
+// ExportKeys writes the parameters and every key of the engine into dir, one file per object.
+// Each file starts with a versioned header holding the fingerprint of the ckks.Parameters,
+// so that the loaders refuse to mix keys generated for different parameters.
+// The directory is created if it does not exist. The secret key file is written with mode 0600.
+func (e *HEEngine) ExportKeys(dir string) error {
+	if err := e.Client.ExportKeys(dir); err != nil {
+		return err
+	}
+	return e.Server.ExportKeys(dir)
+}
+
+// ExportKeys writes the parameters, the secret key and the public key into dir.
+func (c *Client) ExportKeys(dir string) error {
+	if err := exportParams(dir, c.fingerprint, c.isBTS, c.params, c.btpParams); err != nil {
+		return err
+	}
+	if err := writeKeyFile(dir, SecretKeyFile, 0o600, secretKeyMagic, c.fingerprint, c.Sk); err != nil {
+		return err
+	}
+	return writeKeyFile(dir, PublicKeyFile, 0o644, publicKeyMagic, c.fingerprint, c.Pk)
+}
+
+// ExportKeys writes the parameters and the evaluation keys into dir.
+// The resulting directory is all a Server needs and contains no secret material.
+func (e *Server) ExportKeys(dir string) error {
+	if e.evaluator == nil {
+		return fmt.Errorf("a simulated server has no keys")
+	}
+	if err := exportParams(dir, e.fingerprint, e.IsBTS, e.params, e.btpParams); err != nil {
+		return err
+	}
+	if err := writeKeyFile(dir, EvalKeyFile, 0o644, evalKeyMagic, e.fingerprint, rlwe.NewMemEvaluationKeySet(e.Rlk, e.Gks...)); err != nil {
+		return err
+	}
+	if e.IsBTS {
+		return writeKeyFile(dir, BtsKeyFile, 0o644, btsKeyMagic, e.fingerprint, e.Evk)
+	}
+	return nil
+}
+
+// LoadHEEngine restores an HEEngine from the files written by ExportKeys.
+// No key is generated, so ciphertexts encrypted by the exporting engine can be decrypted
+// and the bootstrapping keys do not have to be generated again.
+// Parameters estimated below 128-bit security are refused, see LoadHEEngineInsecure to use them anyway.
+func LoadHEEngine(dir string) (*HEEngine, error) {
+	return loadHEEngine(dir, false)
+}
+
+// LoadHEEngineInsecure is LoadHEEngine accepting parameters estimated below 128-bit security,
+// such as those of config.Test.
+func LoadHEEngineInsecure(dir string) (*HEEngine, error) {
+	return loadHEEngine(dir, true)
+}
+
+func loadHEEngine(dir string, insecure bool) (*HEEngine, error) {
+	client, err := loadClient(dir, insecure)
+	if err != nil {
+		return nil, err
+	}
+	server, err := LoadServer(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &HEEngine{Client: client, Server: server}, nil
+}
+
+// LoadClient restores a Client from the parameters and the key pair written by ExportKeys.
+// Parameters estimated below 128-bit security are refused, see LoadClientInsecure to use them anyway.
+func LoadClient(dir string) (*Client, error) {
+	return loadClient(dir, false)
+}
+
+// LoadClientInsecure is LoadClient accepting parameters estimated below 128-bit security,
+// such as those of config.Test.
+func LoadClientInsecure(dir string) (*Client, error) {
+	return loadClient(dir, true)
+}
+
+// loadClient restores a Client, checking the security of the parameters unless insecure is set.
+func loadClient(dir string, insecure bool) (*Client, error) {
+	fp, isBTS, params, btpParams, err := loadParams(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !insecure {
+		if err = config.CheckSecurity(isBTS, params, btpParams); err != nil {
+			return nil, err
+		}
+	}
+
+	sk := rlwe.NewSecretKey(params)
+	pk := rlwe.NewPublicKey(params)
+	if err = readKeyFile(dir, SecretKeyFile, secretKeyMagic, fp, sk); err != nil {
+		return nil, err
+	}
+	if err = readKeyFile(dir, PublicKeyFile, publicKeyMagic, fp, pk); err != nil {
+		return nil, err
+	}
+	return newClient(isBTS, params, btpParams, sk, pk)
+}
+
+// LoadServer restores a Server from the parameters and the evaluation keys written by ExportKeys.
+// The secret key file is never opened.
+func LoadServer(dir string) (*Server, error) {
+	fp, isBTS, params, btpParams, err := loadParams(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	evk := rlwe.NewMemEvaluationKeySet(nil)
+	if err = readKeyFile(dir, EvalKeyFile, evalKeyMagic, fp, evk); err != nil {
+		return nil, err
+	}
+	var btsEvk *bootstrapping.EvaluationKeys
+	if isBTS {
+		btsEvk = new(bootstrapping.EvaluationKeys)
+		if err = readKeyFile(dir, BtsKeyFile, btsKeyMagic, fp, btsEvk); err != nil {
+			return nil, err
+		}
+	}
+
+	return newServerFromKeySet(isBTS, params, btpParams, evk, btsEvk)
+}
+
+// WriteEvaluationKeys writes the parameters and the evaluation keys on w, in the format read by ReadServer.
+// This is what a data owner sends to the party running the Server.
+func (c *Client) WriteEvaluationKeys(w io.Writer, evk *EvaluationKeys) error {
+	bw := bufio.NewWriter(w)
+	if err := writeParams(bw, c.fingerprint, c.isBTS, c.params, c.btpParams); err != nil {
+		return fmt.Errorf("write parameters: %w", err)
+	}
+	if err := writeKey(bw, evalKeyMagic, c.fingerprint, rlwe.NewMemEvaluationKeySet(evk.Rlk, evk.Gks...)); err != nil {
+		return fmt.Errorf("write evaluation keys: %w", err)
+	}
+	if c.isBTS {
+		if evk.Evk == nil {
+			return fmt.Errorf("bootstrapping is enabled but no bootstrapping keys were given")
+		}
+		if err := writeKey(bw, btsKeyMagic, c.fingerprint, evk.Evk); err != nil {
+			return fmt.Errorf("write bootstrapping keys: %w", err)
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadServer builds a Server from the parameters and evaluation keys written by Client.WriteEvaluationKeys.
+func ReadServer(r io.Reader) (*Server, error) {
+	br := bufio.NewReader(r)
+	fp, isBTS, params, btpParams, err := readParams(br)
+	if err != nil {
+		return nil, fmt.Errorf("read parameters: %w", err)
+	}
+
+	evk := rlwe.NewMemEvaluationKeySet(nil)
+	if err = readKey(br, evalKeyMagic, fp, evk); err != nil {
+		return nil, fmt.Errorf("read evaluation keys: %w", err)
+	}
+	var btsEvk *bootstrapping.EvaluationKeys
+	if isBTS {
+		btsEvk = new(bootstrapping.EvaluationKeys)
+		if err = readKey(br, btsKeyMagic, fp, btsEvk); err != nil {
+			return nil, fmt.Errorf("read bootstrapping keys: %w", err)
+		}
+	}
+
+	return newServerFromKeySet(isBTS, params, btpParams, evk, btsEvk)
+}
+
+// newServerFromKeySet builds a Server from a deserialized key set, checking that every Galois key required by the Server
+// is present. The keys of the rotations to the right are optional, so that key sets exported before they existed still load.
+func newServerFromKeySet(isBTS bool, params ckks.Parameters, btpParams bootstrapping.Parameters, evk *rlwe.MemEvaluationKeySet, btsEvk *bootstrapping.EvaluationKeys) (*Server, error) {
+	if evk.RelinearizationKey == nil {
+		return nil, fmt.Errorf("missing relinearization key")
+	}
+	gks := make([]*rlwe.GaloisKey, 0, len(evk.GaloisKeys))
+	for _, galEl := range requiredGaloisElements(params) {
+		gk, ok := evk.GaloisKeys[galEl]
+		if !ok {
+			return nil, fmt.Errorf("missing Galois key for element %d", galEl)
+		}
+		gks = append(gks, gk)
+	}
+	for _, galEl := range rightRotationElements(params) {
+		if gk, ok := evk.GaloisKeys[galEl]; ok {
+			gks = append(gks, gk)
+		}
+	}
+
+	return NewServer(isBTS, params, btpParams, &EvaluationKeys{Rlk: evk.RelinearizationKey, Gks: gks, Evk: btsEvk})
+}
+
+func exportParams(dir string, fp Fingerprint, isBTS bool, params ckks.Parameters, btpParams bootstrapping.Parameters) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create key directory: %w", err)
+	}
+	if err := writeFile(filepath.Join(dir, ParamsFile), 0o644, func(w io.Writer) error {
+		return writeParams(w, fp, isBTS, params, btpParams)
+	}); err != nil {
+		return fmt.Errorf("export parameters: %w", err)
+	}
+	return nil
+}
+
+func loadParams(dir string) (fp Fingerprint, isBTS bool, params ckks.Parameters, btpParams bootstrapping.Parameters, err error) {
+	if err = readFile(filepath.Join(dir, ParamsFile), func(r io.Reader) (err error) {
+		fp, isBTS, params, btpParams, err = readParams(r)
+		return err
+	}); err != nil {
+		err = fmt.Errorf("load parameters: %w", err)
+	}
+	return
+}
+
+// writeKeyFile writes a header followed by a single key into dir/name.
+func writeKeyFile(dir, name string, perm os.FileMode, magic [4]byte, fp Fingerprint, key io.WriterTo) error {
+	err := writeFile(filepath.Join(dir, name), perm, func(w io.Writer) error {
+		return writeKey(w, magic, fp, key)
+	})
+	if err != nil {
+		return fmt.Errorf("export %s: %w", name, err)
+	}
+	return nil
+}
+
+// readKeyFile reads a key written by writeKeyFile and checks that it belongs to the parameters identified by fp.
+func readKeyFile(dir, name string, magic [4]byte, fp Fingerprint, key io.ReaderFrom) error {
+	err := readFile(filepath.Join(dir, name), func(r io.Reader) error {
+		return readKey(r, magic, fp, key)
+	})
+	if err != nil {
+		return fmt.Errorf("load %s: %w", name, err)
+	}
+	return nil
+}
+
+func writeKey(w io.Writer, magic [4]byte, fp Fingerprint, key io.WriterTo) error {
+	if _, err := writeHeader(w, magic, keyFormatVersion, fp); err != nil {
+		return err
+	}
+	_, err := key.WriteTo(w)
+	return err
+}
+
+func readKey(r io.Reader, magic [4]byte, fp Fingerprint, key io.ReaderFrom) error {
+	keyFp, _, _, err := readHeader(r, magic, keyFormatVersion)
+	if err != nil {
+		return err
+	}
+	if keyFp != fp {
+		return fmt.Errorf("key belongs to parameters %s, expected %s", keyFp, fp)
+	}
+	_, err = key.ReadFrom(r)
+	return err
+}
+
+func writeParams(w io.Writer, fp Fingerprint, isBTS bool, params ckks.Parameters, btpParams bootstrapping.Parameters) error {
+	if _, err := writeHeader(w, paramsMagic, keyFormatVersion, fp); err != nil {
+		return err
+	}
+	flag := []byte{0}
+	if isBTS {
+		flag[0] = 1
+	}
+	if _, err := w.Write(flag); err != nil {
+		return err
+	}
+	data, err := params.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("marshal ckks parameters: %w", err)
+	}
+	if _, err = writeBlob(w, data); err != nil {
+		return err
+	}
+	if !isBTS {
+		return nil
+	}
+	if data, err = btpParams.MarshalBinary(); err != nil {
+		return fmt.Errorf("marshal bootstrapping parameters: %w", err)
+	}
+	_, err = writeBlob(w, data)
+	return err
+}
+
+func readParams(r io.Reader) (fp Fingerprint, isBTS bool, params ckks.Parameters, btpParams bootstrapping.Parameters, err error) {
+	if fp, _, _, err = readHeader(r, paramsMagic, keyFormatVersion); err != nil {
+		return
+	}
+	flag := make([]byte, 1)
+	if _, err = io.ReadFull(r, flag); err != nil {
+		return
+	}
+	isBTS = flag[0] == 1
+
+	var data []byte
+	if data, _, err = readBlob(r); err != nil {
+		return
+	}
+	if err = params.UnmarshalBinary(data); err != nil {
+		err = fmt.Errorf("unmarshal ckks parameters: %w", err)
+		return
+	}
+	var actual Fingerprint
+	if actual, err = ParamsFingerprint(params); err != nil {
+		return
+	}
+	if actual != fp {
+		err = fmt.Errorf("parameters fingerprint %s does not match header %s", actual, fp)
+		return
+	}
+
+	if isBTS {
+		if data, _, err = readBlob(r); err != nil {
+			return
+		}
+		if err = btpParams.UnmarshalBinary(data); err != nil {
+			err = fmt.Errorf("unmarshal bootstrapping parameters: %w", err)
+		}
+	}
+	return
+}
+
+func writeFile(name string, perm os.FileMode, fn func(w io.Writer) error) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if err = fn(w); err != nil {
+		f.Close()
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readFile(name string, fn func(r io.Reader) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return fn(bufio.NewReader(f))
+}
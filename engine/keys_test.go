@@ -0,0 +1,109 @@
+package engine_test
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hm-choi/pp-stat/config"
+	"github.com/hm-choi/pp-stat/engine"
+)
+
+func newTestEngine(t *testing.T) *engine.HEEngine {
+	t.Helper()
+	p := config.Test
+	p.IsBTS = false
+	p.Insecure = true
+	e, err := engine.NewHEEngineFromParameters(p)
+	if err != nil {
+		t.Fatalf("NewHEEngineFromParameters: %v", err)
+	}
+	return e
+}
+
+func TestKeyFilesVersion1(t *testing.T) {
+	e := newTestEngine(t)
+	dir := t.TempDir()
+	if err := e.ExportKeys(dir); err != nil {
+		t.Fatalf("ExportKeys: %v", err)
+	}
+
+	// Key directories written before HEData got its own version must keep loading, so the
+	// key files stay at version 1 whatever the version of the HEData encoding.
+	for _, name := range []string{engine.ParamsFile, engine.SecretKeyFile, engine.PublicKeyFile, engine.EvalKeyFile} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		if v := binary.LittleEndian.Uint16(data[4:]); v != 1 {
+			t.Errorf("%s: format version %d, want 1", name, v)
+		}
+	}
+
+	loaded, err := engine.LoadHEEngineInsecure(dir)
+	if err != nil {
+		t.Fatalf("LoadHEEngineInsecure: %v", err)
+	}
+	column := []float64{1, 2, 3}
+	d, err := e.Encrypt(column)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	checkValues(t, loaded.Client, d, column)
+}
+
+func TestLoadInsecure(t *testing.T) {
+	dir := t.TempDir()
+	if err := newTestEngine(t).ExportKeys(dir); err != nil {
+		t.Fatalf("ExportKeys: %v", err)
+	}
+	if _, err := engine.LoadHEEngine(dir); err == nil {
+		t.Errorf("LoadHEEngine accepted insecure parameters")
+	}
+	if _, err := engine.LoadClient(dir); err == nil {
+		t.Errorf("LoadClient accepted insecure parameters")
+	}
+	if _, err := engine.LoadClientInsecure(dir); err != nil {
+		t.Errorf("LoadClientInsecure: %v", err)
+	}
+}
+
+func TestLoadServerWithoutRightRotations(t *testing.T) {
+	e := newTestEngine(t)
+	params := e.Params()
+
+	// Drop the keys of the rotations to the right, as in the key directories of earlier versions.
+	// The rotations by half the slots are the same in both directions.
+	right := map[uint64]bool{}
+	for rot := 1; rot < params.MaxSlots()/2; rot *= 2 {
+		right[params.GaloisElement(-rot)] = true
+	}
+	gks := e.Server.Gks[:0:0]
+	for _, gk := range e.Server.Gks {
+		if !right[gk.GaloisElement] {
+			gks = append(gks, gk)
+		}
+	}
+	e.Server.Gks = gks
+
+	dir := t.TempDir()
+	if err := e.ExportKeys(dir); err != nil {
+		t.Fatalf("ExportKeys: %v", err)
+	}
+	server, err := engine.LoadServer(dir)
+	if err != nil {
+		t.Fatalf("LoadServer: %v", err)
+	}
+
+	ct, err := e.Encrypt([]float64{1, 2, 3})
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if _, err = server.Backend().Rotate(ct, 1); err != nil {
+		t.Errorf("rotation to the left: %v", err)
+	}
+	if _, err = server.Backend().Rotate(ct, -1); err == nil {
+		t.Errorf("rotation to the right succeeded without its Galois key")
+	}
+}
@@ -0,0 +1,376 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/minimax"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/polynomial"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// lattigoBackend is the Backend evaluating CKKS ciphertexts with Lattigo.
+// It uses the evaluators and the worker pool of its Server.
+type lattigoBackend struct {
+	s *Server
+}
+
+// data returns an HEData holding ctxts, with the size and scale of like and the level of the ciphertexts.
+func (b lattigoBackend) data(ctxts []*rlwe.Ciphertext, like *HEData) *HEData {
+	return NewHEData(ctxts, like.Size(), ctxts[0].Level(), like.Scale())
+}
+
+func (b lattigoBackend) Add(a, c *HEData) (*HEData, error) {
+	return b.binary(a, c, "AddNew", func(w *worker, ct0, ct1 *rlwe.Ciphertext) (*rlwe.Ciphertext, error) {
+		return w.eval.AddNew(ct0, ct1)
+	})
+}
+
+func (b lattigoBackend) Sub(a, c *HEData) (*HEData, error) {
+	return b.binary(a, c, "SubNew", func(w *worker, ct0, ct1 *rlwe.Ciphertext) (*rlwe.Ciphertext, error) {
+		return w.eval.SubNew(ct0, ct1)
+	})
+}
+
+// binary applies op to the ciphertexts of a and c with the same index. The ciphertexts of the
+// longer operand without counterpart are copied to the result.
+func (b lattigoBackend) binary(a, c *HEData, name string, op func(w *worker, ct0, ct1 *rlwe.Ciphertext) (*rlwe.Ciphertext, error)) (*HEData, error) {
+	// Get ciphertext slices
+	ctxts1 := a.Ciphertexts()
+	ctxts2 := c.Ciphertexts()
+	ctLen1 := len(ctxts1)
+	ctLen2 := len(ctxts2)
+	ctNum := max(ctLen1, ctLen2)
+
+	// Prepare output slice
+	result := make([]*rlwe.Ciphertext, ctNum)
+
+	// Perform element-wise operation
+	err := b.s.forEach(ctNum, func(w *worker, i int) error {
+		switch {
+		case i < ctLen1 && i < ctLen2:
+			// Both slices have ciphertext at index i
+			ct, err := op(w, ctxts1[i], ctxts2[i])
+			if err != nil {
+				return fmt.Errorf("%s failed at index %d: %w", name, i, err)
+			}
+			result[i] = ct
+
+		case i >= ctLen1:
+			// Only c has a ciphertext
+			result[i] = ctxts2[i]
+
+		case i >= ctLen2:
+			// Only a has a ciphertext
+			result[i] = ctxts1[i]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.data(result, a), nil
+}
+
+func (b lattigoBackend) AddConst(a *HEData, con float64) (*HEData, error) {
+	ctxts := make([]*rlwe.Ciphertext, a.NumCiphertexts())
+	err := b.s.forEach(len(ctxts), func(w *worker, i int) error {
+		ct, err := w.eval.AddNew(a.Ciphertexts()[i], con)
+		if err != nil {
+			return fmt.Errorf("addition failed at index %d: %w", i, err)
+		}
+		ctxts[i] = ct
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.data(ctxts, a), nil
+}
+
+func (b lattigoBackend) Mult(a, c *HEData) (*HEData, error) {
+	ctxts1 := a.Ciphertexts()
+	ctxts2 := c.Ciphertexts()
+	result := make([]*rlwe.Ciphertext, min(len(ctxts1), len(ctxts2)))
+
+	err := b.s.forEach(len(result), func(w *worker, i int) error {
+		ct1 := ctxts1[i].CopyNew()
+		ct2 := ctxts2[i].CopyNew()
+		ct, err := w.eval.MulRelinNew(ct1, ct2)
+		if err != nil {
+			return fmt.Errorf("MulRelinNew failed at index %d: %w", i, err)
+		}
+
+		// Rescale to default scale
+		if err = w.eval.Rescale(ct, ct); err != nil {
+			return fmt.Errorf("Rescale failed at index %d: %w", i, err)
+		}
+		result[i] = ct
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.data(result, a), nil
+}
+
+func (b lattigoBackend) MultInt(a *HEData, con int) (*HEData, error) {
+	ctxts := make([]*rlwe.Ciphertext, a.NumCiphertexts())
+	err := b.s.forEach(len(ctxts), func(w *worker, i int) error {
+		ct, err := w.eval.MulNew(a.Ciphertexts()[i], con)
+		if err != nil {
+			return fmt.Errorf("MulNew failed at index %d: %w", i, err)
+		}
+		ctxts[i] = ct
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.data(ctxts, a), nil
+}
+
+func (b lattigoBackend) MultMask(a *HEData, con float64) (*HEData, error) {
+	ctxts := make([]*rlwe.Ciphertext, a.NumCiphertexts())
+
+	// The i-th ciphertext holds the slots [i*slots, (i+1)*slots)
+	slots := b.s.params.MaxSlots()
+	size := a.Size()
+	err := b.s.forEach(len(ctxts), func(w *worker, i int) error {
+		consts := make([]float64, slots)
+		for j := 0; j < min(max(size-i*slots, 0), slots); j++ {
+			consts[j] = con
+		}
+
+		ct, err := w.eval.MulNew(a.Ciphertexts()[i], consts)
+		if err != nil {
+			return fmt.Errorf("MulNew failed at index %d: %w", i, err)
+		}
+		// Rescale to default scale
+		if err = w.eval.Rescale(ct, ct); err != nil {
+			return fmt.Errorf("Rescale failed at index %d: %w", i, err)
+		}
+		ctxts[i] = ct
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.data(ctxts, a), nil
+}
+
+// MultVec encodes each vector at the scale of the modulus of the current level, so the rescaling
+// leaves the scale unchanged.
+func (b lattigoBackend) MultVec(a *HEData, vecs [][]float64) (*HEData, error) {
+	ctxts := make([]*rlwe.Ciphertext, a.NumCiphertexts())
+	err := b.s.forEach(len(ctxts), func(w *worker, i int) error {
+		ct, err := w.eval.MulNew(a.Ciphertexts()[i], vecs[i])
+		if err != nil {
+			return fmt.Errorf("MulNew failed at index %d: %w", i, err)
+		}
+		if err = w.eval.Rescale(ct, ct); err != nil {
+			return fmt.Errorf("Rescale failed at index %d: %w", i, err)
+		}
+		ctxts[i] = ct
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.data(ctxts, a), nil
+}
+
+func (b lattigoBackend) AddVec(a *HEData, vecs [][]float64) (*HEData, error) {
+	ctxts := make([]*rlwe.Ciphertext, a.NumCiphertexts())
+	err := b.s.forEach(len(ctxts), func(w *worker, i int) error {
+		ct, err := w.eval.AddNew(a.Ciphertexts()[i], vecs[i])
+		if err != nil {
+			return fmt.Errorf("addition failed at index %d: %w", i, err)
+		}
+		ctxts[i] = ct
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.data(ctxts, a), nil
+}
+
+// Rotate decomposes k into rotations by powers of two of the same sign, which are the ones
+// with a Galois key.
+func (b lattigoBackend) Rotate(a *HEData, k int) (*HEData, error) {
+	sign := 1
+	if k < 0 {
+		sign, k = -1, -k
+	}
+	k %= b.s.params.MaxSlots()
+	if sign < 0 {
+		for rot := 1; rot <= k; rot *= 2 {
+			if k&rot == 0 {
+				continue
+			}
+			if _, err := b.s.evaluator.CheckAndGetGaloisKey(b.s.params.GaloisElement(-rot)); err != nil {
+				return nil, fmt.Errorf("rotation by %d needs the Galois keys of the rotations to the right, which keys exported by earlier versions lack; generate the evaluation keys again: %w", -rot, err)
+			}
+		}
+	}
+
+	ctxts := make([]*rlwe.Ciphertext, a.NumCiphertexts())
+	err := b.s.forEach(len(ctxts), func(w *worker, i int) error {
+		ct := a.Ciphertexts()[i].CopyNew()
+		for rot := 1; rot <= k; rot *= 2 {
+			if k&rot == 0 {
+				continue
+			}
+			if err := w.eval.Rotate(ct, sign*rot, ct); err != nil {
+				return fmt.Errorf("rotation by %d failed at index %d: %w", sign*rot, i, err)
+			}
+		}
+		ctxts[i] = ct
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.data(ctxts, a), nil
+}
+
+// Sum adds the ciphertexts first, then the slots with log2(slots) rotations.
+// It is sequential, so that the order of the additions does not depend on the number of workers.
+func (b lattigoBackend) Sum(a *HEData) (*HEData, error) {
+	eval := b.s.evaluator
+	ctxt := a.Ciphertexts()[0].CopyNew()
+	if len(a.Ciphertexts()) > 1 {
+		for i := 1; i < len(a.Ciphertexts()); i++ {
+			eval.Add(ctxt, a.Ciphertexts()[i], ctxt)
+		}
+	}
+
+	for i := 0; i < b.s.params.LogMaxSlots(); i++ {
+		rot := 1 << i
+		tmp, err := eval.RotateNew(ctxt, rot)
+		if err != nil {
+			return nil, fmt.Errorf("rotation failed at %d: %w", rot, err)
+		}
+		if err = eval.Add(ctxt, tmp, ctxt); err != nil {
+			return nil, fmt.Errorf("addition failed: %w", err)
+		}
+	}
+
+	ctxts := make([]*rlwe.Ciphertext, len(a.Ciphertexts()))
+	for i := 0; i < len(a.Ciphertexts()); i++ {
+		ctxts[i] = ctxt.CopyNew()
+	}
+	return b.data(ctxts, a), nil
+}
+
+func (b lattigoBackend) Replicate(a *HEData, n int) (*HEData, error) {
+	ctxts := make([]*rlwe.Ciphertext, n)
+	for i := 0; i < n; i++ {
+		ctxts[i] = a.Ciphertexts()[0].CopyNew()
+	}
+	return b.data(ctxts, a), nil
+}
+
+// Bootstrap relabels the scale of each ciphertext to twice the default scale and adds its
+// conjugate, which keeps the real part of the slots, before bootstrapping it.
+func (b lattigoBackend) Bootstrap(a *HEData) (*HEData, error) {
+	params := b.s.params
+	ctxts := make([]*rlwe.Ciphertext, a.NumCiphertexts())
+	err := b.s.forEach(len(ctxts), func(w *worker, i int) error {
+		ct := a.Ciphertexts()[i].CopyNew()
+		ct.Scale = params.DefaultScale().Mul(rlwe.NewScale(2))
+		conj, err := w.eval.ConjugateNew(ct)
+		if err != nil {
+			return fmt.Errorf("conjugate failed at index %d: %w", i, err)
+		}
+		if ct, err = w.eval.AddNew(conj, ct); err != nil {
+			return fmt.Errorf("addition failed at index %d: %w", i, err)
+		}
+		if ct, err = w.bts.Bootstrap(ct); err != nil {
+			return fmt.Errorf("bootstrap failed at index %d: %w", i, err)
+		}
+		ctxts[i] = ct
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.data(ctxts, a), nil
+}
+
+// EvalPoly evaluates poly at half the default scale, then doubles the scale and adds the conjugate.
+// The scale of the result is relabeled to the one of the input.
+func (b lattigoBackend) EvalPoly(a *HEData, poly bignum.Polynomial) (*HEData, error) {
+	params := b.s.params
+	p := polynomial.NewPolynomial(poly)
+	targetScale := params.DefaultScale().Div(rlwe.NewScale(2))
+
+	ctxts := make([]*rlwe.Ciphertext, a.NumCiphertexts())
+	err := b.s.forEach(len(ctxts), func(w *worker, i int) error {
+		polyEval := polynomial.NewEvaluator(params, w.eval)
+		p2, err := polyEval.Evaluate(a.Ciphertexts()[i], p, targetScale)
+		if err != nil {
+			return fmt.Errorf("polynomial evaluation failed at index %d: %w", i, err)
+		}
+		p2.Scale = p2.Scale.Mul(rlwe.NewScale(2))
+		conj, err := w.eval.ConjugateNew(p2)
+		if err != nil {
+			return fmt.Errorf("conjugate failed at index %d: %w", i, err)
+		}
+		if err = w.eval.Add(p2, conj, p2); err != nil {
+			return fmt.Errorf("addition failed at index %d: %w", i, err)
+		}
+		p2.Scale = a.Ciphertexts()[i].Scale
+		ctxts[i] = p2
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.data(ctxts, a), nil
+}
+
+// hookBootstrapper calls hook after each bootstrap.
+type hookBootstrapper struct {
+	bootstrapping.Bootstrapper
+	hook func(level int) error
+}
+
+func (h hookBootstrapper) Bootstrap(ct *rlwe.Ciphertext) (*rlwe.Ciphertext, error) {
+	ct, err := h.Bootstrapper.Bootstrap(ct)
+	if err != nil {
+		return nil, err
+	}
+	return ct, h.hook(ct.Level())
+}
+
+// EvalComposite uses the minimax evaluator of Lattigo. The calls to onBootstrap are serialized.
+func (b lattigoBackend) EvalComposite(a *HEData, polys []bignum.Polynomial, onBootstrap func(level int) error) (*HEData, error) {
+	var mu sync.Mutex
+	hook := func(level int) error {
+		if onBootstrap == nil {
+			return nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return onBootstrap(level)
+	}
+
+	ctxts := make([]*rlwe.Ciphertext, a.NumCiphertexts())
+	err := b.s.forEach(len(ctxts), func(w *worker, i int) error {
+		eval := minimax.NewEvaluator(b.s.params, w.eval, hookBootstrapper{Bootstrapper: w.bts, hook: hook})
+		ct, err := eval.Evaluate(a.Ciphertexts()[i], minimax.Polynomial(polys))
+		if err != nil {
+			return fmt.Errorf("composite polynomial evaluation failed at index %d: %w", i, err)
+		}
+		ctxts[i] = ct
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.data(ctxts, a), nil
+}
@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// LogisticModel is the encrypted result of LogisticRegression. Its values are replicated in every slot like Sum.
+type LogisticModel struct {
+	// Weights holds the weight of every feature, in the order of the features.
+	Weights []*HEData
+	// Intercept is the bias added to the weighted features.
+	Intercept *HEData
+}
+
+// sigmoidBound is the bound K of the interval [-K, K] on which the sigmoid is approximated.
+const sigmoidBound = 8.0
+
+// LogisticRegression trains P(label = 1) = sigmoid(Intercept + Σ Weights[j]·features[j]) by iters steps of
+// full-batch gradient descent with the learning rate lr, from zero weights. label must hold 0 or 1 on every
+// row, like a yes/no column read by utils.ReadCSV.
+//
+// The sigmoid is replaced by its Chebyshev approximation of degree 29 on [-8, 8], so the features must be
+// scaled such that the linear predictor stays in [-8, 8] during the training, e.g. standardized. Every
+// iteration bootstraps the linear predictor once and the weights once. Bootstrapping is required.
+func (e *Server) LogisticRegression(features []*HEData, label *HEData, iters int, lr float64) (*LogisticModel, error) {
+	return e.LogisticRegressionCtx(context.Background(), features, label, iters, lr)
+}
+
+// LogisticRegressionCtx is LogisticRegression returning the error of ctx as soon as it is done, which is
+// checked between two bootstraps or iterations. Its progress is reported to e.Progress.
+func (e *Server) LogisticRegressionCtx(ctx context.Context, features []*HEData, label *HEData, iters int, lr float64) (*LogisticModel, error) {
+	const chebyshevDepth = 5
+
+	if !e.IsBTS {
+		return nil, fmt.Errorf("logistic regression requires bootstrapping")
+	}
+	if len(features) == 0 {
+		return nil, fmt.Errorf("no features")
+	}
+	if iters < 1 {
+		return nil, fmt.Errorf("the number of iterations must be positive, got %d", iters)
+	}
+	for j, ct := range features {
+		if ct.Size() != label.Size() {
+			return nil, fmt.Errorf("feature %d has %d rows but the label has %d", j, ct.Size(), label.Size())
+		}
+	}
+
+	sigmoid := GetChebyshevPoly(sigmoidBound, int(math.Pow(2, chebyshevDepth))-2, func(x float64) float64 {
+		return 1 / (1 + math.Exp(-x))
+	})
+	// A gradient step is -lr times the mean over the rows, i.e. a sum divided by -N/lr
+	stepDenom := -float64(label.Size()) / lr
+
+	model := &LogisticModel{Weights: make([]*HEData, len(features))}
+	for t := range iters {
+		// Step 1: Prediction sigmoid(b + Σ w[j]·x[j]), which is 0.5 for the zero weights of the first iteration
+		var residual *HEData
+		var err error
+		if t == 0 {
+			if residual, err = e.MultConst(label, -1); err != nil {
+				return nil, fmt.Errorf("-label: %w", err)
+			}
+			if residual, err = e.AddConst(residual, 0.5); err != nil {
+				return nil, fmt.Errorf("0.5 - label: %w", err)
+			}
+		} else {
+			z, err := e.logisticPredictor(ctx, model, features)
+			if err != nil {
+				return nil, fmt.Errorf("iteration %d: %w", t+1, err)
+			}
+			if z, err = e.doBootstrap(ctx, z, sigmoid.Depth()+3); err != nil {
+				return nil, fmt.Errorf("iteration %d: bootstrap linear predictor: %w", t+1, err)
+			}
+			if z, err = e.MultConst(z, 1/sigmoidBound); err != nil {
+				return nil, fmt.Errorf("iteration %d: scale into [-1, 1]: %w", t+1, err)
+			}
+			prob, err := e.evalPoly(z, sigmoid)
+			if err != nil {
+				return nil, fmt.Errorf("iteration %d: sigmoid: %w", t+1, err)
+			}
+
+			// Step 2: Residual sigmoid(z) - label
+			if residual, err = e.Sub(prob, label); err != nil {
+				return nil, fmt.Errorf("iteration %d: residual: %w", t+1, err)
+			}
+		}
+
+		// Step 3: Gradient steps w[j] -= lr·mean(residual·x[j]) and b -= lr·mean(residual)
+		for j, x := range features {
+			grad, err := e.Mult(residual, x)
+			if err != nil {
+				return nil, fmt.Errorf("iteration %d: residual·x[%d]: %w", t+1, j, err)
+			}
+			if grad, err = meanWithCustomDenom(e, grad, stepDenom); err != nil {
+				return nil, fmt.Errorf("iteration %d: gradient of w[%d]: %w", t+1, j, err)
+			}
+			if model.Weights[j], err = e.logisticUpdate(model.Weights[j], grad); err != nil {
+				return nil, fmt.Errorf("iteration %d: update w[%d]: %w", t+1, j, err)
+			}
+		}
+		grad, err := meanWithCustomDenom(e, residual, stepDenom)
+		if err != nil {
+			return nil, fmt.Errorf("iteration %d: gradient of b: %w", t+1, err)
+		}
+		if model.Intercept, err = e.logisticUpdate(model.Intercept, grad); err != nil {
+			return nil, fmt.Errorf("iteration %d: update b: %w", t+1, err)
+		}
+
+		if err := e.checkpoint(ctx, ProgressEvent{Stage: StageGradient, Iteration: t + 1, Level: model.Intercept.Level()}); err != nil {
+			return nil, err
+		}
+	}
+
+	// Step 4: Replicate the weights like Sum
+	for j, w := range model.Weights {
+		var err error
+		if model.Weights[j], err = e.extendOneToMulty(w, label.NumCiphertexts(), label.Size()); err != nil {
+			return nil, fmt.Errorf("extendOneToMulty: %w", err)
+		}
+	}
+	var err error
+	if model.Intercept, err = e.extendOneToMulty(model.Intercept, label.NumCiphertexts(), label.Size()); err != nil {
+		return nil, fmt.Errorf("extendOneToMulty: %w", err)
+	}
+	return model, nil
+}
+
+// logisticPredictor returns b + Σ w[j]·x[j] on the rows of the features. The weights, which are kept on
+// one ciphertext during the training, are bootstrapped and replicated to the size of the features.
+func (e *Server) logisticPredictor(ctx context.Context, model *LogisticModel, features []*HEData) (*HEData, error) {
+	like := features[0]
+	var err error
+	if model.Intercept, err = e.doBootstrap(ctx, model.Intercept, 1); err != nil {
+		return nil, fmt.Errorf("bootstrap b: %w", err)
+	}
+	z, err := e.extendOneToMulty(model.Intercept, like.NumCiphertexts(), like.Size())
+	if err != nil {
+		return nil, fmt.Errorf("extendOneToMulty(b): %w", err)
+	}
+	for j, x := range features {
+		if model.Weights[j], err = e.doBootstrap(ctx, model.Weights[j], 1); err != nil {
+			return nil, fmt.Errorf("bootstrap w[%d]: %w", j, err)
+		}
+		w, err := e.extendOneToMulty(model.Weights[j], like.NumCiphertexts(), like.Size())
+		if err != nil {
+			return nil, fmt.Errorf("extendOneToMulty(w[%d]): %w", j, err)
+		}
+		wx, err := e.Mult(x, w)
+		if err != nil {
+			return nil, fmt.Errorf("w[%d]·x[%d]: %w", j, j, err)
+		}
+		if z, err = e.Add(z, wx); err != nil {
+			return nil, fmt.Errorf("add w[%d]·x[%d]: %w", j, j, err)
+		}
+	}
+	return z, nil
+}
+
+// logisticUpdate returns w + step, on the first ciphertext of the replicated step, or step for the zero weight w = nil.
+func (e *Server) logisticUpdate(w, step *HEData) (*HEData, error) {
+	step, err := e.selectOneCtxt(step)
+	if err != nil {
+		return nil, err
+	}
+	if w == nil {
+		return step, nil
+	}
+	return e.Add(w, step)
+}
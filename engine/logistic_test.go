@@ -0,0 +1,75 @@
+package engine_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hm-choi/pp-stat/engine"
+)
+
+// logisticDescent trains the model of LogisticRegression in plaintext, with the exact sigmoid.
+func logisticDescent(x [][]float64, label []float64, iters int, lr float64) ([]float64, float64) {
+	w, b := make([]float64, len(x)), 0.0
+	n := float64(len(label))
+	for range iters {
+		grad, gradB := make([]float64, len(x)), 0.0
+		for i := range label {
+			z := b
+			for j := range x {
+				z += w[j] * x[j][i]
+			}
+			residual := 1/(1+math.Exp(-z)) - label[i]
+			for j := range x {
+				grad[j] += residual * x[j][i]
+			}
+			gradB += residual
+		}
+		for j := range w {
+			w[j] -= lr * grad[j] / n
+		}
+		b -= lr * gradB / n
+	}
+	return w, b
+}
+
+func TestSimLogisticRegression(t *testing.T) {
+	e := newSimEngine(t, true)
+	const iters, lr = 5, 1.0
+	n := 200
+	x := [][]float64{uniform(n, 4, 17), uniform(n, 4, 18)}
+	noise := uniform(n, 2, 19)
+	label := make([]float64, n)
+	for i := range label {
+		x[0][i] -= 2
+		x[1][i] -= 2
+		if x[0][i]-0.5*x[1][i]+noise[i]-1 > 0 {
+			label[i] = 1
+		}
+	}
+	wantW, wantB := logisticDescent(x, label, iters, lr)
+
+	features := make([]*engine.HEData, len(x))
+	for j := range x {
+		ct, err := e.Encrypt(x[j])
+		if err != nil {
+			t.Fatalf("Encrypt: %v", err)
+		}
+		features[j] = ct
+	}
+	ctLabel, err := e.Encrypt(label)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	model, err := e.LogisticRegression(features, ctLabel, iters, lr)
+	if err != nil {
+		t.Fatalf("LogisticRegression: %v", err)
+	}
+	for j := range wantW {
+		if got := decryptSim(t, e, model.Weights[j], nil)[0]; math.Abs(got-wantW[j]) > 1e-3 {
+			t.Errorf("weight %d = %v, want %v", j, got, wantW[j])
+		}
+	}
+	if got := decryptSim(t, e, model.Intercept, nil)[0]; math.Abs(got-wantB) > 1e-3 {
+		t.Errorf("intercept = %v, want %v", got, wantB)
+	}
+}
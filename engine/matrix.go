@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+)
+
+// CovarianceMatrix returns the covariances E[(X - μx)(Y - μy)] of every pair of columns of cols,
+// replicated in every slot like Sum. The matrix is symmetric: the entries [i][j] and [j][i] are the same
+// HEData, and the diagonal holds the variances. Each column is centered once for all the pairs.
+func (e *Server) CovarianceMatrix(cols []*HEData) ([][]*HEData, error) {
+	centered, err := e.centerColumns(cols)
+	if err != nil {
+		return nil, err
+	}
+	return e.covariances(centered)
+}
+
+// CorrelationMatrix returns the Pearson correlation coefficients of every pair of columns of cols,
+// replicated in every slot like Sum. The matrix is symmetric like the one of CovarianceMatrix.
+//
+// Each entry is computed as by PCorrCoeff, but the mean and the inverse standard deviation of every
+// column are computed once for all the pairs: k columns take k inverse square roots instead of k².
+func (e *Server) CorrelationMatrix(cols []*HEData, B float64) ([][]*HEData, error) {
+	return e.CorrelationMatrixCtx(context.Background(), cols, B)
+}
+
+// CorrelationMatrixCtx is CorrelationMatrix returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps or Newton iterations. Its progress is reported to e.Progress.
+func (e *Server) CorrelationMatrixCtx(ctx context.Context, cols []*HEData, B float64) ([][]*HEData, error) {
+	corr, _, _, err := e.correlations(ctx, cols, B)
+	return corr, err
+}
+
+// correlations returns the correlation and covariance matrices of cols, as CorrelationMatrix and
+// CovarianceMatrix, and the inverse standard deviation of every column.
+func (e *Server) correlations(ctx context.Context, cols []*HEData, B float64) (corr, cov [][]*HEData, invStd []*HEData, err error) {
+	// The constants of PCorrCoeff
+	const (
+		chebyshevDegree = 2
+		newtonIter      = 6
+		newtonScale     = 2
+		bootstrapDepth  = 3
+	)
+
+	// Step 1: Covariances of the centered columns
+	centered, err := e.centerColumns(cols)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if cov, err = e.covariances(centered); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Step 2: Inverse standard deviation of every column
+	invStd = make([]*HEData, len(cols))
+	for i, ct := range cols {
+		if invStd[i], err = e.computeInvStd(ctx, ct, chebyshevDegree, newtonIter, newtonScale, bootstrapDepth, B); err != nil {
+			return nil, nil, nil, fmt.Errorf("computeInvStd (column %d): %w", i, err)
+		}
+	}
+
+	// Step 3: Correlation = cov × (1/σx) × (1/σy)
+	corr = newSymmetric(len(cols))
+	for i := range cols {
+		for j := i; j < len(cols); j++ {
+			if corr[i][j], err = e.normalizeCovariance(cov[i][j], invStd[i], invStd[j]); err != nil {
+				return nil, nil, nil, fmt.Errorf("correlation of columns %d and %d: %w", i, j, err)
+			}
+			corr[j][i] = corr[i][j]
+		}
+	}
+	return corr, cov, invStd, nil
+}
+
+// centerColumns returns X - μx for every column X of cols, which must have the same size.
+func (e *Server) centerColumns(cols []*HEData) ([]*HEData, error) {
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("no columns")
+	}
+	centered := make([]*HEData, len(cols))
+	for i, ct := range cols {
+		if ct.Size() != cols[0].Size() {
+			return nil, fmt.Errorf("column %d has %d rows but column 0 has %d", i, ct.Size(), cols[0].Size())
+		}
+		mean, err := e.Mean(ct)
+		if err != nil {
+			return nil, fmt.Errorf("mean of column %d: %w", i, err)
+		}
+		if centered[i], err = e.Sub(ct, mean); err != nil {
+			return nil, fmt.Errorf("center column %d: %w", i, err)
+		}
+	}
+	return centered, nil
+}
+
+// covariances returns the symmetric matrix of the means E[X·Y] of the pairs of centered columns.
+func (e *Server) covariances(centered []*HEData) ([][]*HEData, error) {
+	cov := newSymmetric(len(centered))
+	for i := range centered {
+		for j := i; j < len(centered); j++ {
+			mulXY, err := e.Mult(centered[i], centered[j])
+			if err != nil {
+				return nil, fmt.Errorf("x·y of columns %d and %d: %w", i, j, err)
+			}
+			if cov[i][j], err = e.Mean(mulXY); err != nil {
+				return nil, fmt.Errorf("mean of x·y of columns %d and %d: %w", i, j, err)
+			}
+			cov[j][i] = cov[i][j]
+		}
+	}
+	return cov, nil
+}
+
+// newSymmetric returns an empty k×k matrix.
+func newSymmetric(k int) [][]*HEData {
+	m := make([][]*HEData, k)
+	for i := range m {
+		m[i] = make([]*HEData, k)
+	}
+	return m
+}
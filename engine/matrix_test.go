@@ -0,0 +1,51 @@
+package engine_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hm-choi/pp-stat/engine"
+	"github.com/hm-choi/pp-stat/utils"
+)
+
+func TestSimCovarianceCorrelationMatrix(t *testing.T) {
+	e := newSimEngine(t, true)
+	const B = 10.0
+	x := uniform(200, B, 12)
+	noise := uniform(200, B, 13)
+	cols := [][]float64{x, make([]float64, len(x)), noise}
+	for i := range x {
+		cols[1][i] = 0.7*x[i] + 0.3*noise[i]
+	}
+	cts := make([]*engine.HEData, len(cols))
+	for k, col := range cols {
+		ct, err := e.Encrypt(col)
+		if err != nil {
+			t.Fatalf("Encrypt: %v", err)
+		}
+		cts[k] = ct
+	}
+
+	cov, err := e.CovarianceMatrix(cts)
+	if err != nil {
+		t.Fatalf("CovarianceMatrix: %v", err)
+	}
+	corr, err := e.CorrelationMatrix(cts, B)
+	if err != nil {
+		t.Fatalf("CorrelationMatrix: %v", err)
+	}
+	for i := range cols {
+		for j := range cols {
+			wantCov, wantCorr, err := utils.Correlation(cols[i], cols[j])
+			if err != nil {
+				t.Fatalf("utils.Correlation: %v", err)
+			}
+			if got := decryptSim(t, e, cov[i][j], nil)[0]; math.Abs(got-wantCov) > 1e-4 {
+				t.Errorf("covariance [%d][%d] = %v, want %v", i, j, got, wantCov)
+			}
+			if got := decryptSim(t, e, corr[i][j], nil)[0]; math.Abs(got-wantCorr) > 1e-4 {
+				t.Errorf("correlation [%d][%d] = %v, want %v", i, j, got, wantCorr)
+			}
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// Median returns the median of ct, replicated in every slot like Sum.
+// The values of ct must lie in [-B, B]; see Quantile.
+func (e *Server) Median(ct *HEData, B float64) (*HEData, error) {
+	return e.QuantileCtx(context.Background(), ct, 0.5, B)
+}
+
+// MedianCtx is Median returning the error of ctx as soon as it is done. Its progress is reported to e.Progress.
+func (e *Server) MedianCtx(ctx context.Context, ct *HEData, B float64) (*HEData, error) {
+	return e.QuantileCtx(ctx, ct, 0.5, B)
+}
+
+// Quantile returns the q-quantile of ct for q in [0, 1], replicated in every slot like Sum.
+// As in most statistics packages, it interpolates linearly between the two values of ct whose
+// ranks surround q·(Size-1).
+//
+// The values of ct must lie in [-B, B]: they are divided by 2B so that the difference of two of
+// them, on which the comparisons are evaluated, lies in [-1, 1]. The values are sorted by a bitonic
+// sorting network, whose log2(n)·(log2(n)+1)/2 stages for n values rounded up to a power of two
+// each evaluate a step function and bootstrap. Bootstrapping is required.
+func (e *Server) Quantile(ct *HEData, q, B float64) (*HEData, error) {
+	return e.QuantileCtx(context.Background(), ct, q, B)
+}
+
+// QuantileCtx is Quantile returning the error of ctx as soon as it is done, which is checked
+// between two stages of the sorting network or bootstraps. Its progress is reported to e.Progress.
+func (e *Server) QuantileCtx(ctx context.Context, ct *HEData, q, B float64) (*HEData, error) {
+	quantiles, err := e.QuantilesCtx(ctx, ct, []float64{q}, B)
+	if err != nil {
+		return nil, err
+	}
+	return quantiles[0], nil
+}
+
+// Quantiles returns the q-quantile of ct for every q of qs, e.g. the quartiles for 0.25, 0.5 and 0.75.
+// The values are sorted once for all the quantiles; see Quantile.
+func (e *Server) Quantiles(ct *HEData, qs []float64, B float64) ([]*HEData, error) {
+	return e.QuantilesCtx(context.Background(), ct, qs, B)
+}
+
+// QuantilesCtx is Quantiles returning the error of ctx as soon as it is done. Its progress is reported to e.Progress.
+func (e *Server) QuantilesCtx(ctx context.Context, ct *HEData, qs []float64, B float64) ([]*HEData, error) {
+	for _, q := range qs {
+		if q < 0 || q > 1 || math.IsNaN(q) {
+			return nil, fmt.Errorf("quantile %v is not in [0, 1]", q)
+		}
+	}
+
+	// Step 1: Sort x/2B in ascending order
+	sorted, err := e.sortCtx(ctx, ct, B)
+	if err != nil {
+		return nil, fmt.Errorf("sort: %w", err)
+	}
+	if sorted, err = e.doBootstrap(ctx, sorted, 1); err != nil {
+		return nil, fmt.Errorf("bootstrap sorted values: %w", err)
+	}
+
+	// Step 2: Select the values around q·(n-1), weighted by the interpolation and multiplied back by 2B
+	slots := e.params.MaxSlots()
+	idx := make([]int, ct.NumCiphertexts())
+	for i := range idx {
+		idx[i] = i
+	}
+	quantiles := make([]*HEData, len(qs))
+	for k, q := range qs {
+		h := q * float64(ct.Size()-1)
+		lo := int(math.Floor(h))
+		frac := h - float64(lo)
+
+		weights := make([][]float64, sorted.NumCiphertexts())
+		for c := range weights {
+			weights[c] = make([]float64, slots)
+		}
+		weights[lo/slots][lo%slots] = (1 - frac) * 2 * B
+		if frac > 0 {
+			weights[(lo+1)/slots][(lo+1)%slots] = frac * 2 * B
+		}
+
+		selected, err := e.multVec(sorted, weights, false)
+		if err != nil {
+			return nil, fmt.Errorf("select rank %d: %w", lo, err)
+		}
+
+		// Step 3: Replicate the quantile in every slot
+		quantile, err := e.Sum(selected)
+		if err != nil {
+			return nil, fmt.Errorf("sum of rank %d: %w", lo, err)
+		}
+		quantiles[k] = quantile.pick(idx)
+	}
+	return quantiles, nil
+}
+
+// ColumnMax returns the largest value of ct, replicated in every slot like Sum.
+//
+// The values of ct must lie in [-B, B]: they are divided by 2B so that the difference of two of
+// them, on which the comparisons are evaluated, lies in [-1, 1]. The maximum is found by a
+// tournament: the ciphertexts are compared pairwise, then the slots of the remaining ciphertext with
+// its rotations by slots/2, slots/4, ..., 1, with a bootstrapping between two rounds.
+// Bootstrapping is required.
+func (e *Server) ColumnMax(ct *HEData, B float64) (*HEData, error) {
+	return e.ColumnMaxCtx(context.Background(), ct, B)
+}
+
+// ColumnMaxCtx is ColumnMax returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps. Its progress is reported to e.Progress.
+func (e *Server) ColumnMaxCtx(ctx context.Context, ct *HEData, B float64) (*HEData, error) {
+	return e.columnExtremum(ctx, ct, B, true)
+}
+
+// ColumnMin returns the smallest value of ct, replicated in every slot like Sum. See ColumnMax.
+func (e *Server) ColumnMin(ct *HEData, B float64) (*HEData, error) {
+	return e.ColumnMinCtx(context.Background(), ct, B)
+}
+
+// ColumnMinCtx is ColumnMin returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps. Its progress is reported to e.Progress.
+func (e *Server) ColumnMinCtx(ctx context.Context, ct *HEData, B float64) (*HEData, error) {
+	return e.columnExtremum(ctx, ct, B, false)
+}
+
+// columnExtremum returns the maximum of ct if isMax is set, and its minimum otherwise.
+func (e *Server) columnExtremum(ctx context.Context, ct *HEData, B float64, isMax bool) (*HEData, error) {
+	if !e.IsBTS {
+		return nil, fmt.Errorf("comparisons require bootstrapping")
+	}
+	if B <= 0 {
+		return nil, fmt.Errorf("bound B must be positive: %v", B)
+	}
+
+	// Step 1: Append copies of the first ciphertext to get a power of two of them
+	slots := e.params.MaxSlots()
+	numCt := nextPowerOfTwo(ct.NumCiphertexts())
+	idx := make([]int, numCt)
+	for i := range min(numCt, ct.NumCiphertexts()) {
+		idx[i] = i
+	}
+	x := ct.pick(idx)
+
+	// Step 2: Scale the values into [-1/2, 1/2] and set the padding to the value losing every comparison
+	loser := -0.5
+	if !isMax {
+		loser = 0.5
+	}
+	scale, fill := make([][]float64, numCt), make([][]float64, numCt)
+	for c := range numCt {
+		scale[c], fill[c] = make([]float64, slots), make([]float64, slots)
+		for i := range slots {
+			if c*slots+i < ct.Size() {
+				scale[c][i] = 1 / (2 * B)
+			} else {
+				fill[c][i] = loser
+			}
+		}
+	}
+	x, err := e.multVec(x, scale, false)
+	if err != nil {
+		return nil, fmt.Errorf("scale by 1/2B: %w", err)
+	}
+	if x, err = e.addVec(x, fill, true); err != nil {
+		return nil, fmt.Errorf("fill padding: %w", err)
+	}
+
+	// Step 3: Compare the first half of the ciphertexts with the second half until one is left
+	for n := numCt / 2; n >= 1; n /= 2 {
+		first, second := make([]int, n), make([]int, n)
+		for i := range n {
+			first[i], second[i] = i, n+i
+		}
+		if x, err = e.extremum(ctx, x.pick(first), x.pick(second), isMax); err != nil {
+			return nil, fmt.Errorf("compare %d ciphertexts: %w", 2*n, err)
+		}
+	}
+
+	// Step 4: Compare the slots with their rotations, which leaves the extremum in every slot
+	for rot := slots / 2; rot >= 1; rot /= 2 {
+		rotated, err := e.rotate(x, rot)
+		if err != nil {
+			return nil, fmt.Errorf("rotate by %d: %w", rot, err)
+		}
+		if x, err = e.extremum(ctx, x, rotated, isMax); err != nil {
+			return nil, fmt.Errorf("compare slots at distance %d: %w", rot, err)
+		}
+	}
+
+	// Step 5: Multiply back by 2B and replicate like Sum
+	if x, err = e.doBootstrap(ctx, x, 1); err != nil {
+		return nil, fmt.Errorf("bootstrap: %w", err)
+	}
+	scale = [][]float64{make([]float64, slots)}
+	for i := range scale[0] {
+		scale[0][i] = 2 * B
+	}
+	if x, err = e.multVec(x, scale, true); err != nil {
+		return nil, fmt.Errorf("scale by 2B: %w", err)
+	}
+	return e.replicate(x, ct.NumCiphertexts(), ct.Size(), true)
+}
+
+// extremum returns the smooth maximum b + step(a-b)·(a-b) of a and b if isMax is set,
+// and their smooth minimum a - step(a-b)·(a-b) otherwise, as Evaluator.Max and Evaluator.Min do.
+// a - b must lie in [-1, 1].
+func (e *Server) extremum(ctx context.Context, a, b *HEData, isMax bool) (*HEData, error) {
+	a, err := e.doBootstrap(ctx, a, 2)
+	if err != nil {
+		return nil, fmt.Errorf("bootstrap: %w", err)
+	}
+	if b, err = e.doBootstrap(ctx, b, 2); err != nil {
+		return nil, fmt.Errorf("bootstrap: %w", err)
+	}
+
+	diff, err := e.Sub(a, b)
+	if err != nil {
+		return nil, fmt.Errorf("a - b: %w", err)
+	}
+	step, err := e.step(ctx, diff)
+	if err != nil {
+		return nil, fmt.Errorf("step: %w", err)
+	}
+	if step, err = e.doBootstrap(ctx, step, 2); err != nil {
+		return nil, fmt.Errorf("bootstrap step: %w", err)
+	}
+	stepDiff, err := e.Mult(step, diff)
+	if err != nil {
+		return nil, fmt.Errorf("step·diff: %w", err)
+	}
+	if isMax {
+		return e.Add(b, stepDiff)
+	}
+	return e.Sub(a, stepDiff)
+}
+
+// sortCtx returns the values of ct divided by 2B and sorted in ascending order by a bitonic sorting network.
+//
+// A single ciphertext is sorted on its first Size slots rounded up to a power of two, and several
+// ciphertexts on all their slots, after copies of the first ciphertext are appended to get a power of
+// two of them. The slots of this range after Size are set to 1/2, the largest value, so they end up
+// after the values of ct. The result has as many ciphertexts as the sorted range.
+func (e *Server) sortCtx(ctx context.Context, ct *HEData, B float64) (*HEData, error) {
+	if !e.IsBTS {
+		return nil, fmt.Errorf("sorting requires bootstrapping")
+	}
+	if B <= 0 {
+		return nil, fmt.Errorf("bound B must be positive: %v", B)
+	}
+
+	slots := e.params.MaxSlots()
+	numCt := nextPowerOfTwo(ct.NumCiphertexts())
+	width := slots
+	if numCt == 1 {
+		width = nextPowerOfTwo(ct.Size())
+	}
+
+	// Step 1: Append copies of the first ciphertext
+	idx := make([]int, numCt)
+	for i := range min(numCt, ct.NumCiphertexts()) {
+		idx[i] = i
+	}
+	x := ct.pick(idx)
+
+	// Step 2: Scale the values into [-1/2, 1/2] and set the rest of the sorted range to 1/2
+	scale, fill := make([][]float64, numCt), make([][]float64, numCt)
+	for c := range numCt {
+		scale[c], fill[c] = make([]float64, slots), make([]float64, slots)
+		for i := range width {
+			if c*slots+i < ct.Size() {
+				scale[c][i] = 1 / (2 * B)
+			} else {
+				fill[c][i] = 0.5
+			}
+		}
+	}
+	x, err := e.multVec(x, scale, false)
+	if err != nil {
+		return nil, fmt.Errorf("scale by 1/2B: %w", err)
+	}
+	if x, err = e.addVec(x, fill, true); err != nil {
+		return nil, fmt.Errorf("fill padding: %w", err)
+	}
+
+	// Step 3: Sorting network
+	stage := 0
+	for k := 2; k <= numCt*width; k *= 2 {
+		for j := k / 2; j >= 1; j /= 2 {
+			stage++
+			if x, err = e.compareExchange(ctx, x, k, j, width); err != nil {
+				return nil, fmt.Errorf("stage %d: %w", stage, err)
+			}
+			if err = e.checkpoint(ctx, ProgressEvent{Stage: StageSort, Iteration: stage, Level: x.Level()}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return x, nil
+}
+
+// compareExchange evaluates the stage (k, j) of the bitonic sorting network on the first width slots
+// of every ciphertext of x, the i-th slot of the c-th ciphertext having the index c·slots+i.
+// The values at indices g and g^j are exchanged so that the smaller comes first if g&k is zero,
+// and last otherwise. The other slots are left unchanged.
+func (e *Server) compareExchange(ctx context.Context, x *HEData, k, j, width int) (*HEData, error) {
+	slots := e.params.MaxSlots()
+	numCt := x.NumCiphertexts()
+
+	x, err := e.doBootstrap(ctx, x, 3)
+	if err != nil {
+		return nil, fmt.Errorf("bootstrap: %w", err)
+	}
+
+	// takeMax is 1 where the larger value of the pair is kept, sign is -1 where the smaller one is kept and 1 otherwise.
+	lower, upper := make([][]float64, numCt), make([][]float64, numCt)
+	takeMax, sign := make([][]float64, numCt), make([][]float64, numCt)
+	for c := range numCt {
+		lower[c], upper[c] = make([]float64, slots), make([]float64, slots)
+		takeMax[c], sign[c] = make([]float64, slots), make([]float64, slots)
+		for i := range width {
+			g := c*slots + i
+			if g&j == 0 {
+				lower[c][i] = 1
+			} else {
+				upper[c][i] = 1
+			}
+			if (g&j == 0) == (g&k == 0) {
+				sign[c][i] = -1
+			} else {
+				takeMax[c][i], sign[c][i] = 1, 1
+			}
+		}
+	}
+
+	// Step 1: Bring the other value of each pair to the slot of x
+	var partner *HEData
+	if j < slots {
+		next, err := e.rotate(x, j)
+		if err != nil {
+			return nil, fmt.Errorf("rotate by %d: %w", j, err)
+		}
+		prev, err := e.rotate(x, -j)
+		if err != nil {
+			return nil, fmt.Errorf("rotate by %d: %w", -j, err)
+		}
+		if next, err = e.multVec(next, lower, true); err != nil {
+			return nil, fmt.Errorf("mask lower slots: %w", err)
+		}
+		if prev, err = e.multVec(prev, upper, true); err != nil {
+			return nil, fmt.Errorf("mask upper slots: %w", err)
+		}
+		if partner, err = e.Add(next, prev); err != nil {
+			return nil, fmt.Errorf("partner: %w", err)
+		}
+	} else {
+		idx := make([]int, numCt)
+		for c := range idx {
+			idx[c] = c ^ (j / slots)
+		}
+		partner = x.pick(idx)
+	}
+
+	// Step 2: step(x - partner) is 1 where x is the larger value
+	diff, err := e.Sub(x, partner)
+	if err != nil {
+		return nil, fmt.Errorf("x - partner: %w", err)
+	}
+	step, err := e.step(ctx, diff)
+	if err != nil {
+		return nil, fmt.Errorf("step: %w", err)
+	}
+	if step, err = e.doBootstrap(ctx, step, 2); err != nil {
+		return nil, fmt.Errorf("bootstrap step: %w", err)
+	}
+
+	// Step 3: min = x - step·diff and max = partner + step·diff = x - diff + step·diff
+	stepDiff, err := e.Mult(step, diff)
+	if err != nil {
+		return nil, fmt.Errorf("step·diff: %w", err)
+	}
+	if stepDiff, err = e.multVec(stepDiff, sign, true); err != nil {
+		return nil, fmt.Errorf("sign of step·diff: %w", err)
+	}
+	maxDiff, err := e.multVec(diff, takeMax, true)
+	if err != nil {
+		return nil, fmt.Errorf("diff of max slots: %w", err)
+	}
+	if x, err = e.Add(x, stepDiff); err != nil {
+		return nil, fmt.Errorf("add step·diff: %w", err)
+	}
+	return e.Sub(x, maxDiff)
+}
+
+// nextPowerOfTwo returns the smallest power of two larger than or equal to n.
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p *= 2
+	}
+	return p
+}
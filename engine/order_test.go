@@ -0,0 +1,74 @@
+package engine_test
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+// quantile is the q-quantile of sorted, interpolated linearly as Quantile does.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	if lo == len(sorted)-1 {
+		return sorted[lo]
+	}
+	return sorted[lo] + (pos-float64(lo))*(sorted[lo+1]-sorted[lo])
+}
+
+func TestSimQuantiles(t *testing.T) {
+	e := newSimEngine(t, true)
+	const B = 10.0
+	values := uniform(100, 2*B, 6)
+	for i := range values {
+		values[i] -= B
+	}
+	ct, err := e.Encrypt(values)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	qs := []float64{0, 0.25, 0.5, 0.75, 1}
+	quantiles, err := e.Quantiles(ct, qs, B)
+	if err != nil {
+		t.Fatalf("Quantiles: %v", err)
+	}
+	for i, q := range qs {
+		got := decryptSim(t, e, quantiles[i], nil)
+		if want := quantile(sorted, q); math.Abs(got[0]-want) > 1e-3 {
+			t.Errorf("quantile %v: got %v, want %v", q, got[0], want)
+		}
+	}
+
+	median, err := e.Median(ct, B)
+	if got := decryptSim(t, e, median, err); math.Abs(got[0]-quantile(sorted, 0.5)) > 1e-3 {
+		t.Errorf("Median: got %v, want %v", got[0], quantile(sorted, 0.5))
+	}
+}
+
+func TestSimColumnExtrema(t *testing.T) {
+	e := newSimEngine(t, true)
+	const B = 10.0
+	// More values than slots, so that the tournament runs across the ciphertexts first.
+	values := uniform(e.Slots+100, 2*B, 7)
+	for i := range values {
+		values[i] -= B
+	}
+	ct, err := e.Encrypt(values)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	maximum, err := e.ColumnMax(ct, B)
+	if got := decryptSim(t, e, maximum, err); math.Abs(got[0]-sorted[len(sorted)-1]) > 1e-3 {
+		t.Errorf("ColumnMax: got %v, want %v", got[0], sorted[len(sorted)-1])
+	}
+	minimum, err := e.ColumnMin(ct, B)
+	if got := decryptSim(t, e, minimum, err); math.Abs(got[0]-sorted[0]) > 1e-3 {
+		t.Errorf("ColumnMin: got %v, want %v", got[0], sorted[0])
+	}
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/dft"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/mod1"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/polynomial"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+// worker holds the evaluators used by one goroutine of forEach.
+// The evaluators of lattigo keep internal buffers and must not be shared between goroutines.
+type worker struct {
+	eval *ckks.Evaluator
+	bts  *bootstrapping.Evaluator
+}
+
+// workerPool returns n workers. The first one uses the evaluators of the Server, the
+// others shallow copies of them, which are kept for the next calls.
+func (e *Server) workerPool(n int) []*worker {
+	if len(e.workers) == 0 {
+		e.workers = []*worker{{eval: e.evaluator, bts: e.BTS}}
+	}
+	for len(e.workers) < n {
+		w := &worker{eval: e.evaluator.ShallowCopy()}
+		if e.BTS != nil {
+			w.bts = shallowCopyBootstrapper(e.BTS)
+		}
+		e.workers = append(e.workers, w)
+	}
+	return e.workers[:max(n, 1)]
+}
+
+// forEach calls f(w, i) for every i in [0, n), distributing the indices over up to e.Workers goroutines.
+// f must only write to the i-th element of its output, so that the result does not depend on the
+// number of workers. The error of the smallest failing index is returned.
+func (e *Server) forEach(n int, f func(w *worker, i int) error) error {
+	workers := e.workerPool(min(e.Workers, n))
+
+	if len(workers) == 1 {
+		for i := 0; i < n; i++ {
+			if err := f(workers[0], i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	errs := make([]error, n)
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Add(1)
+		go func(w *worker) {
+			defer wg.Done()
+			for i := int(next.Add(1) - 1); i < n; i = int(next.Add(1) - 1) {
+				errs[i] = f(w, i)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shallowCopyBootstrapper returns a bootstrapping evaluator sharing the keys and the DFT matrices of bts.
+// bootstrapping.Evaluator.ShallowCopy builds its DFT and Mod1 evaluators on the residual parameters
+// instead of the bootstrapping ones, so they are rebuilt here as in bootstrapping.NewEvaluator.
+func shallowCopyBootstrapper(bts *bootstrapping.Evaluator) *bootstrapping.Evaluator {
+	cp := bts.ShallowCopy()
+	params := cp.BootstrappingParameters
+	cp.DFTEvaluator = dft.NewEvaluator(params, cp.Evaluator)
+	cp.Mod1Evaluator = mod1.NewEvaluator(cp.Evaluator, polynomial.NewEvaluator(params, cp.Evaluator), cp.Mod1Parameters)
+	return cp
+}
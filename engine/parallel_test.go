@@ -0,0 +1,64 @@
+package engine_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hm-choi/pp-stat/config"
+	"github.com/hm-choi/pp-stat/engine"
+)
+
+// TestWorkersDeterministic checks that the results with several workers are bit for bit those of a
+// single one, including the bootstrapping, whose extra workers use shallowCopyBootstrapper.
+func TestWorkersDeterministic(t *testing.T) {
+	p := config.Test
+	p.Insecure = true
+	e, err := engine.NewHEEngineFromParameters(p)
+	if err != nil {
+		t.Fatalf("NewHEEngineFromParameters: %v", err)
+	}
+
+	// Four ciphertexts, so that every worker gets one.
+	const B = 4.0
+	values := uniform(4*e.Slots, B, 4)
+	ct, err := e.Encrypt(values)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	ops := map[string]func() (*engine.HEData, error){
+		"Mult": func() (*engine.HEData, error) { return e.Mult(ct, ct) },
+		"Add":  func() (*engine.HEData, error) { return e.Add(ct, ct) },
+		"EvalPoly": func() (*engine.HEData, error) {
+			return e.ChebyshevInvSqrt(ct, 1, B)
+		},
+		"Bootstrap": func() (*engine.HEData, error) {
+			low, err := e.MultConst(ct, 1/B)
+			if err != nil {
+				return nil, err
+			}
+			return e.DoBootstrap(low, p.Level)
+		},
+	}
+	for name, op := range ops {
+		run := func(workers int) []float64 {
+			e.Workers = workers
+			d, err := op()
+			if err != nil {
+				t.Fatalf("%s with %d workers: %v", name, workers, err)
+			}
+			got, err := e.Decrypt(d)
+			if err != nil {
+				t.Fatalf("Decrypt: %v", err)
+			}
+			return got
+		}
+		sequential, parallel := run(1), run(4)
+		for i := range sequential {
+			if math.Float64bits(sequential[i]) != math.Float64bits(parallel[i]) {
+				t.Errorf("%s: value %d is %v with 1 worker and %v with 4", name, i, sequential[i], parallel[i])
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package engine
+
+import "context"
+
+// Stages reported in a ProgressEvent.
+const (
+	StageBootstrap = "bootstrap" // a bootstrapping of the algorithm, e.g. of the Chebyshev initial guess
+	StageNewton    = "newton"    // an iteration of the Newton method
+	StageSign      = "sign"      // a bootstrapping inside the minimax sign evaluation
+	StageSort      = "sort"      // a compare-exchange stage of the bitonic sorting network
+	StageGradient  = "gradient"  // an iteration of gradient descent
+	StageRank      = "rank"      // a batch of pairwise comparisons of Rank
+)
+
+// ProgressEvent describes a step of a long computation that has just been completed.
+type ProgressEvent struct {
+	Stage     string
+	Iteration int // 1-based iteration within the stage, 0 for a stage without iterations
+	Level     int // level of the ciphertext after the step
+}
+
+// ProgressFunc receives the ProgressEvents of the ...Ctx methods of a Server.
+type ProgressFunc func(ProgressEvent)
+
+// checkpoint returns the error of ctx if it is done. Otherwise it reports ev to e.Progress, if set.
+func (e *Server) checkpoint(ctx context.Context, ev ProgressEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if e.Progress != nil {
+		e.Progress(ev)
+	}
+	return nil
+}
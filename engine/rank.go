@@ -0,0 +1,180 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+)
+
+// Rank returns the rank of every value of ct, from 1 for the smallest to Size for the largest. Tied values
+// get the average of their ranks, as step(0) = 0.5: the rank of x[i] is 1 + Σ step(x[i] - x[j]) over j ≠ i.
+// The error of CKKS moves the difference of two tied values away from zero, so that with real encryption
+// a tie may instead be broken either way, which changes each of the two ranks by up to 1/2.
+//
+// The values of ct must lie in [-B, B]: they are divided by 2B so that the difference of two of them,
+// on which the comparisons are evaluated, lies in [-1, 1]. The Size-1 shifted copies of the column are
+// packed into floor(slots/Size) blocks of a ciphertext, so ct must fit in half a ciphertext and
+// (Size-1)/floor(slots/Size) step functions are evaluated. Bootstrapping is required.
+func (e *Server) Rank(ct *HEData, B float64) (*HEData, error) {
+	return e.RankCtx(context.Background(), ct, B)
+}
+
+// RankCtx is Rank returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps. Its progress is reported to e.Progress.
+func (e *Server) RankCtx(ctx context.Context, ct *HEData, B float64) (*HEData, error) {
+	if !e.IsBTS {
+		return nil, fmt.Errorf("comparisons require bootstrapping")
+	}
+	if B <= 0 {
+		return nil, fmt.Errorf("bound B must be positive: %v", B)
+	}
+	slots := e.params.MaxSlots()
+	n := ct.Size()
+	if n < 2 {
+		return nil, fmt.Errorf("ranking needs at least two rows, got %d", n)
+	}
+	if 2*n > slots {
+		return nil, fmt.Errorf("ranking supports at most %d rows with %d slots, got %d", slots/2, slots, n)
+	}
+	k := slots / n
+
+	// block returns the plaintext vector holding v on the slots of the blocks [b·n, b·n+n) for b in blocks.
+	block := func(v float64, blocks ...int) [][]float64 {
+		vec := make([]float64, slots)
+		for _, b := range blocks {
+			for i := range n {
+				vec[b*n+i] = v
+			}
+		}
+		return [][]float64{vec}
+	}
+
+	// Step 1: x/2B on the first n slots, k copies of it in the blocks, and x followed by itself
+	x, err := e.doBootstrap(ctx, ct, 2)
+	if err != nil {
+		return nil, fmt.Errorf("bootstrap: %w", err)
+	}
+	if x, err = e.multVec(x, block(1/(2*B), 0), false); err != nil {
+		return nil, fmt.Errorf("scale by 1/2B: %w", err)
+	}
+	own, twice := x, x
+	for b := 1; b < k; b++ {
+		copied, err := e.rotate(x, -b*n)
+		if err != nil {
+			return nil, fmt.Errorf("rotate by %d: %w", -b*n, err)
+		}
+		if own, err = e.Add(own, copied); err != nil {
+			return nil, fmt.Errorf("copy to block %d: %w", b, err)
+		}
+	}
+	copied, err := e.rotate(x, -n)
+	if err != nil {
+		return nil, fmt.Errorf("rotate by %d: %w", -n, err)
+	}
+	if twice, err = e.Add(twice, copied); err != nil {
+		return nil, fmt.Errorf("x followed by x: %w", err)
+	}
+
+	// Step 2: Compare every block of own with x shifted by r, for the shifts r = 1, ..., n-1
+	var acc *HEData
+	batch := 0
+	for first := 1; first < n; first += k {
+		batch++
+		shifts := min(k, n-first)
+		used := make([]int, shifts)
+		var partner *HEData
+		for b := range shifts {
+			used[b] = b
+			// the slot b·n+i of the block b receives x[(i+r) mod n] = twice[i+r]
+			shifted, err := e.rotate(twice, first+b-b*n)
+			if err != nil {
+				return nil, fmt.Errorf("rotate by %d: %w", first+b-b*n, err)
+			}
+			if shifted, err = e.multVec(shifted, block(1, b), false); err != nil {
+				return nil, fmt.Errorf("mask block %d: %w", b, err)
+			}
+			if partner == nil {
+				partner = shifted
+			} else if partner, err = e.Add(partner, shifted); err != nil {
+				return nil, fmt.Errorf("partner of block %d: %w", b, err)
+			}
+		}
+
+		diff, err := e.Sub(own, partner)
+		if err != nil {
+			return nil, fmt.Errorf("x - partner: %w", err)
+		}
+		step, err := e.step(ctx, diff)
+		if err != nil {
+			return nil, fmt.Errorf("step: %w", err)
+		}
+		if shifts < k {
+			// the blocks without partner compare x with zero
+			if step, err = e.doBootstrap(ctx, step, 1); err != nil {
+				return nil, fmt.Errorf("bootstrap step: %w", err)
+			}
+			if step, err = e.multVec(step, block(1, used...), false); err != nil {
+				return nil, fmt.Errorf("mask unused blocks: %w", err)
+			}
+		}
+		if acc == nil {
+			acc = step
+		} else if acc, err = e.Add(acc, step); err != nil {
+			return nil, fmt.Errorf("accumulate: %w", err)
+		}
+		if err = e.checkpoint(ctx, ProgressEvent{Stage: StageRank, Iteration: batch, Level: acc.Level()}); err != nil {
+			return nil, err
+		}
+	}
+
+	// Step 3: Add the blocks into the first one and add 1
+	if acc, err = e.doBootstrap(ctx, acc, 1); err != nil {
+		return nil, fmt.Errorf("bootstrap: %w", err)
+	}
+	ranks := acc
+	for b := 1; b < k; b++ {
+		folded, err := e.rotate(acc, b*n)
+		if err != nil {
+			return nil, fmt.Errorf("rotate by %d: %w", b*n, err)
+		}
+		if ranks, err = e.Add(ranks, folded); err != nil {
+			return nil, fmt.Errorf("fold block %d: %w", b, err)
+		}
+	}
+	if ranks, err = e.multVec(ranks, block(1, 0), false); err != nil {
+		return nil, fmt.Errorf("mask first block: %w", err)
+	}
+	return e.addVec(ranks, block(1, 0), false)
+}
+
+// SpearmanCorr returns the Spearman rank correlation coefficient of ct1 and ct2, replicated in every
+// slot like Sum: the Pearson correlation coefficient of their ranks, computed by Rank and divided by
+// Size, with PCorrCoeff. The values of ct1 and ct2 must lie in [-B, B]; see Rank.
+func (e *Server) SpearmanCorr(ct1, ct2 *HEData, B float64) (*HEData, error) {
+	return e.SpearmanCorrCtx(context.Background(), ct1, ct2, B)
+}
+
+// SpearmanCorrCtx is SpearmanCorr returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps or Newton iterations. Its progress is reported to e.Progress.
+func (e *Server) SpearmanCorrCtx(ctx context.Context, ct1, ct2 *HEData, B float64) (*HEData, error) {
+	if ct1.Size() != ct2.Size() {
+		return nil, fmt.Errorf("ct1 has %d rows but ct2 has %d", ct1.Size(), ct2.Size())
+	}
+	ranks := make([]*HEData, 2)
+	for i, ct := range []*HEData{ct1, ct2} {
+		rank, err := e.RankCtx(ctx, ct, B)
+		if err != nil {
+			return nil, fmt.Errorf("rank of ct%d: %w", i+1, err)
+		}
+		if rank, err = e.doBootstrap(ctx, rank, 1); err != nil {
+			return nil, fmt.Errorf("bootstrap rank of ct%d: %w", i+1, err)
+		}
+		// the ranks divided by Size lie in (0, 1]
+		if rank, err = e.MultConst(rank, 1/float64(ct.Size())); err != nil {
+			return nil, fmt.Errorf("scale rank of ct%d: %w", i+1, err)
+		}
+		if ranks[i], err = e.doBootstrap(ctx, rank, e.params.MaxLevel()); err != nil {
+			return nil, fmt.Errorf("bootstrap rank of ct%d: %w", i+1, err)
+		}
+	}
+	return e.PCorrCoeffCtx(ctx, ranks[0], ranks[1], 1)
+}
@@ -0,0 +1,60 @@
+package engine_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hm-choi/pp-stat/utils"
+)
+
+func TestSimRank(t *testing.T) {
+	e := newSimEngine(t, true)
+	const B = 10.0
+	// Integer values, so that the column has ties.
+	values := uniform(100, B, 20)
+	for i := range values {
+		values[i] = math.Floor(values[i])
+	}
+	ct, err := e.Encrypt(values)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	want := utils.Rank(values)
+	d, err := e.Rank(ct, B)
+	got := decryptSim(t, e, d, err)
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-2 {
+			t.Errorf("rank of values[%d] = %v: got %v, want %v", i, values[i], got[i], want[i])
+		}
+	}
+}
+
+func TestSimSpearmanCorr(t *testing.T) {
+	e := newSimEngine(t, true)
+	const B = 10.0
+	x := uniform(100, B, 21)
+	noise := uniform(100, B, 22)
+	y := make([]float64, len(x))
+	for i := range y {
+		// A monotone but non-linear relation, blurred by the noise.
+		y[i] = x[i]*x[i]/B + 0.5*noise[i] - 5
+	}
+	ct1, err := e.Encrypt(x)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	ct2, err := e.Encrypt(y)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	want, err := utils.SpearmanCorr(x, y)
+	if err != nil {
+		t.Fatalf("utils.SpearmanCorr: %v", err)
+	}
+	d, err := e.SpearmanCorr(ct1, ct2, B)
+	if got := decryptSim(t, e, d, err); math.Abs(got[0]-want) > 1e-3 {
+		t.Errorf("SpearmanCorr = %v, want %v", got[0], want)
+	}
+}
@@ -0,0 +1,284 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+)
+
+// LinearModel is the encrypted result of LinearRegression. Its values are replicated in every slot like Sum.
+type LinearModel struct {
+	// Coefficients holds the slope of every feature, in the order of the features.
+	Coefficients []*HEData
+	// Intercept is the value of the prediction when every feature is zero.
+	Intercept *HEData
+	// R2 is the coefficient of determination of the fit.
+	R2 *HEData
+}
+
+// LinearRegression fits y ≈ Intercept + Σ Coefficients[j]·x[j] by ordinary least squares. With a single
+// feature, the slope is cov(x, y)/var(x) and R2 is the square of the Pearson correlation coefficient.
+//
+// The normal equations are solved on the correlation matrix R of the features, whose eigenvalues lie in
+// (0, k] for k features: the standardized coefficients are R⁻¹·r, where r holds the correlations of the
+// features with y, and they are multiplied by σy/σx. R⁻¹ is computed by the Newton iteration
+// X ← X·(2I - R·X) from X = I/k, the matrix form of HENewtonInv, whose error after the last iteration is
+// (1 - λ/k)^1024 for the smallest eigenvalue λ of R: nearly collinear features are not supported.
+//
+// The values of x and y must lie in [-B, B]; B is the bound of the inverse standard deviations, as for
+// PCorrCoeff, whose precision also degrades for columns with a standard deviation much smaller than B.
+// Bootstrapping is required when there is more than one feature.
+func (e *Server) LinearRegression(x []*HEData, y *HEData, B float64) (*LinearModel, error) {
+	return e.LinearRegressionCtx(context.Background(), x, y, B)
+}
+
+// LinearRegressionCtx is LinearRegression returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps or Newton iterations. Its progress is reported to e.Progress.
+func (e *Server) LinearRegressionCtx(ctx context.Context, x []*HEData, y *HEData, B float64) (*LinearModel, error) {
+	const newtonIter = 10
+
+	k := len(x)
+	if k == 0 {
+		return nil, fmt.Errorf("no features")
+	}
+	if k > 1 && !e.IsBTS {
+		return nil, fmt.Errorf("multiple regression requires bootstrapping")
+	}
+
+	// Step 1: Correlations of the features and y, with y as the last column
+	cols := append(append([]*HEData{}, x...), y)
+	corr, cov, invStd, err := e.correlations(ctx, cols, B)
+	if err != nil {
+		return nil, err
+	}
+	r := make([]*HEData, k)
+	for j := range r {
+		if r[j], err = e.scalarOperand(ctx, corr[j][k], 2); err != nil {
+			return nil, fmt.Errorf("correlation of feature %d and y: %w", j, err)
+		}
+	}
+
+	// Step 2: Standardized coefficients R⁻¹·r
+	betaStd := r
+	if k > 1 {
+		R := newSymmetric(k)
+		for i := range k {
+			for j := i; j < k; j++ {
+				if R[i][j], err = e.scalarOperand(ctx, corr[i][j], 2); err != nil {
+					return nil, fmt.Errorf("correlation of features %d and %d: %w", i, j, err)
+				}
+				R[j][i] = R[i][j]
+			}
+		}
+		Rinv, err := e.newtonMatrixInv(ctx, R, 1/float64(k), newtonIter)
+		if err != nil {
+			return nil, fmt.Errorf("inverse of the correlation matrix: %w", err)
+		}
+		if betaStd, err = e.matVec(ctx, Rinv, r); err != nil {
+			return nil, fmt.Errorf("R⁻¹·r: %w", err)
+		}
+	}
+
+	// Step 3: R² = Σ betaStd[j]·r[j]
+	r2, err := e.dot(ctx, betaStd, r)
+	if err != nil {
+		return nil, fmt.Errorf("R²: %w", err)
+	}
+
+	// Step 4: Coefficients betaStd[j]·σy/σx[j], where σy = var(y)·(1/σy)
+	varY, err := e.scalarOperand(ctx, cov[k][k], 2)
+	if err != nil {
+		return nil, fmt.Errorf("variance of y: %w", err)
+	}
+	invStdY, err := e.scalarOperand(ctx, invStd[k], 2)
+	if err != nil {
+		return nil, fmt.Errorf("inverse std of y: %w", err)
+	}
+	stdY, err := e.Mult(varY, invStdY)
+	if err != nil {
+		return nil, fmt.Errorf("std of y: %w", err)
+	}
+	coefficients := make([]*HEData, k)
+	for j := range k {
+		invStdX, err := e.scalarOperand(ctx, invStd[j], 2)
+		if err != nil {
+			return nil, fmt.Errorf("inverse std of feature %d: %w", j, err)
+		}
+		ratio, err := e.Mult(stdY, invStdX)
+		if err != nil {
+			return nil, fmt.Errorf("σy/σx of feature %d: %w", j, err)
+		}
+		if coefficients[j], err = e.scalarMult(ctx, betaStd[j], ratio); err != nil {
+			return nil, fmt.Errorf("coefficient of feature %d: %w", j, err)
+		}
+	}
+
+	// Step 5: Intercept = μy - Σ coefficients[j]·μx[j]
+	means := make([]*HEData, k+1)
+	for j, ct := range cols {
+		mean, err := e.Mean(ct)
+		if err != nil {
+			return nil, fmt.Errorf("mean of column %d: %w", j, err)
+		}
+		if means[j], err = e.scalarOperand(ctx, mean, 2); err != nil {
+			return nil, fmt.Errorf("mean of column %d: %w", j, err)
+		}
+	}
+	predicted, err := e.dot(ctx, coefficients, means[:k])
+	if err != nil {
+		return nil, fmt.Errorf("Σ coefficients·μx: %w", err)
+	}
+	intercept, err := e.Sub(means[k], predicted)
+	if err != nil {
+		return nil, fmt.Errorf("intercept: %w", err)
+	}
+
+	// Step 6: Replicate the results like Sum
+	model := &LinearModel{Coefficients: make([]*HEData, k)}
+	for j, c := range coefficients {
+		if model.Coefficients[j], err = e.extendOneToMulty(c, y.NumCiphertexts(), y.Size()); err != nil {
+			return nil, fmt.Errorf("extendOneToMulty: %w", err)
+		}
+	}
+	if model.Intercept, err = e.extendOneToMulty(intercept, y.NumCiphertexts(), y.Size()); err != nil {
+		return nil, fmt.Errorf("extendOneToMulty: %w", err)
+	}
+	if model.R2, err = e.extendOneToMulty(r2, y.NumCiphertexts(), y.Size()); err != nil {
+		return nil, fmt.Errorf("extendOneToMulty: %w", err)
+	}
+	return model, nil
+}
+
+// newtonMatrixInv returns the inverse of the symmetric matrix a, whose eigenvalues must lie in (0, 2/alpha),
+// by iter Newton iterations X ← X·(2I - a·X) from X = alpha·I. The first one is 2·alpha·I - alpha²·a.
+func (e *Server) newtonMatrixInv(ctx context.Context, a [][]*HEData, alpha float64, iter int) ([][]*HEData, error) {
+	k := len(a)
+	x := newSymmetric(k)
+	for i := range k {
+		for j := range k {
+			xij, err := e.MultConst(a[i][j], -alpha*alpha)
+			if err != nil {
+				return nil, err
+			}
+			if i == j {
+				if xij, err = e.AddConst(xij, 2*alpha); err != nil {
+					return nil, err
+				}
+			}
+			x[i][j] = xij
+		}
+	}
+
+	for t := 1; t < iter; t++ {
+		if e.IsBTS {
+			for i := range k {
+				for j := range k {
+					var err error
+					if x[i][j], err = e.doBootstrap(ctx, x[i][j], 2); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+		ax, err := e.matMul(a, x)
+		if err != nil {
+			return nil, fmt.Errorf("a·X: %w", err)
+		}
+		xax, err := e.matMul(x, ax)
+		if err != nil {
+			return nil, fmt.Errorf("X·a·X: %w", err)
+		}
+		for i := range k {
+			for j := range k {
+				twice, err := e.Add(x[i][j], x[i][j])
+				if err != nil {
+					return nil, err
+				}
+				if x[i][j], err = e.Sub(twice, xax[i][j]); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if err := e.checkpoint(ctx, ProgressEvent{Stage: StageNewton, Iteration: t, Level: x[0][0].Level()}); err != nil {
+			return nil, err
+		}
+	}
+	return x, nil
+}
+
+// matMul returns the product of the square matrices a and b.
+func (e *Server) matMul(a, b [][]*HEData) ([][]*HEData, error) {
+	k := len(a)
+	c := newSymmetric(k)
+	for i := range k {
+		for j := range k {
+			for l := range k {
+				p, err := e.Mult(a[i][l], b[l][j])
+				if err != nil {
+					return nil, err
+				}
+				if c[i][j] == nil {
+					c[i][j] = p
+				} else if c[i][j], err = e.Add(c[i][j], p); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return c, nil
+}
+
+// matVec returns the product of the square matrix a and the vector v.
+func (e *Server) matVec(ctx context.Context, a [][]*HEData, v []*HEData) ([]*HEData, error) {
+	res := make([]*HEData, len(a))
+	for i := range a {
+		var err error
+		if res[i], err = e.dot(ctx, a[i], v); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// dot returns Σ u[j]·v[j].
+func (e *Server) dot(ctx context.Context, u, v []*HEData) (*HEData, error) {
+	var res *HEData
+	for j := range u {
+		p, err := e.scalarMult(ctx, u[j], v[j])
+		if err != nil {
+			return nil, err
+		}
+		if res == nil {
+			res = p
+		} else if res, err = e.Add(res, p); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// scalarMult returns a·b, after bootstrapping an operand which has no level left.
+func (e *Server) scalarMult(ctx context.Context, a, b *HEData) (*HEData, error) {
+	a, err := e.scalarOperand(ctx, a, 1)
+	if err != nil {
+		return nil, err
+	}
+	if b, err = e.scalarOperand(ctx, b, 1); err != nil {
+		return nil, err
+	}
+	return e.Mult(a, b)
+}
+
+// scalarOperand returns the first ciphertext of the replicated value ct, bootstrapped if it has less
+// than level levels left.
+func (e *Server) scalarOperand(ctx context.Context, ct *HEData, level int) (*HEData, error) {
+	ct, err := e.selectOneCtxt(ct)
+	if err != nil {
+		return nil, err
+	}
+	if e.IsBTS {
+		if ct, err = e.doBootstrap(ctx, ct, level); err != nil {
+			return nil, fmt.Errorf("bootstrap: %w", err)
+		}
+	}
+	return ct, nil
+}
@@ -0,0 +1,54 @@
+package engine_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hm-choi/pp-stat/engine"
+	"github.com/hm-choi/pp-stat/utils"
+)
+
+func TestSimLinearRegression(t *testing.T) {
+	e := newSimEngine(t, true)
+	const B = 10.0
+	n := 200
+	x := [][]float64{uniform(n, B, 14), uniform(n, B, 15)}
+	noise := uniform(n, 1, 16)
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 1 + 0.4*x[0][i] - 0.3*x[1][i] + noise[i]
+	}
+	ctY, err := e.Encrypt(y)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	// A single feature and two features, which go through the matrix inverse.
+	for k := 1; k <= len(x); k++ {
+		coef, intercept, r2, err := utils.LinearRegression(x[:k], y)
+		if err != nil {
+			t.Fatalf("utils.LinearRegression: %v", err)
+		}
+		cts := make([]*engine.HEData, k)
+		for j := range cts {
+			if cts[j], err = e.Encrypt(x[j]); err != nil {
+				t.Fatalf("Encrypt: %v", err)
+			}
+		}
+		model, err := e.LinearRegression(cts, ctY, B)
+		if err != nil {
+			t.Fatalf("LinearRegression with %d features: %v", k, err)
+		}
+		for j := range k {
+			if got := decryptSim(t, e, model.Coefficients[j], nil)[0]; math.Abs(got-coef[j]) > 1e-3 {
+				t.Errorf("%d features: coefficient %d = %v, want %v", k, j, got, coef[j])
+			}
+		}
+		if got := decryptSim(t, e, model.Intercept, nil)[0]; math.Abs(got-intercept) > 1e-3 {
+			t.Errorf("%d features: intercept = %v, want %v", k, got, intercept)
+		}
+		if got := decryptSim(t, e, model.R2, nil)[0]; math.Abs(got-r2) > 1e-3 {
+			t.Errorf("%d features: R2 = %v, want %v", k, got, r2)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// Server is the evaluating party. It is built only from the public EvaluationKeys,
+// so it can compute the statistics on encrypted columns but cannot decrypt them.
+type Server struct {
+	params    ckks.Parameters
+	btpParams bootstrapping.Parameters
+	Rlk       *rlwe.RelinearizationKey
+	Gks       []*rlwe.GaloisKey
+	Evk       *bootstrapping.EvaluationKeys
+	evaluator *ckks.Evaluator
+	BTS       *bootstrapping.Evaluator
+	Slots     int
+	IsBTS     bool
+
+	// AutoBootstrap makes Mult, MultConst and the polynomial evaluators bootstrap an operand
+	// whose remaining depth is too small, instead of failing. It requires IsBTS.
+	AutoBootstrap bool
+
+	// Progress, if set, receives the progress of the ...Ctx methods.
+	Progress ProgressFunc
+
+	// Workers is the number of goroutines used to process the ciphertexts of an HEData.
+	// Values smaller than 2 process them one after another. The results do not depend on it.
+	Workers int
+
+	backend     Backend
+	cmpOnce     sync.Once
+	cmp         *Evaluator
+	bootstraps  atomic.Int64
+	workers     []*worker
+	fingerprint Fingerprint
+}
+
+// NewServer builds a Server from the evaluation keys generated by a Client.
+func NewServer(isBTS bool, params ckks.Parameters, btpParams bootstrapping.Parameters, evk *EvaluationKeys) (*Server, error) {
+	if err := checkParams(isBTS, params); err != nil {
+		return nil, err
+	}
+	if evk == nil {
+		return nil, fmt.Errorf("no evaluation keys were given")
+	}
+	fp, err := ParamsFingerprint(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var eval *ckks.Evaluator
+
+	var bts *bootstrapping.Evaluator
+
+	if isBTS {
+		if evk.Evk == nil {
+			return nil, fmt.Errorf("bootstrapping is enabled but no bootstrapping keys were given")
+		}
+		eval = ckks.NewEvaluator(params, evk.Evk)
+		eval = eval.WithKey(rlwe.NewMemEvaluationKeySet(evk.Rlk, evk.Gks...))
+		if bts, err = bootstrapping.NewEvaluator(btpParams, evk.Evk); err != nil {
+			return nil, fmt.Errorf("bootstrapping evaluator: %w", err)
+		}
+	} else {
+		eval = ckks.NewEvaluator(params, rlwe.NewMemEvaluationKeySet(evk.Rlk))
+		eval = eval.WithKey(rlwe.NewMemEvaluationKeySet(evk.Rlk, evk.Gks...))
+	}
+
+	e := &Server{
+		params:      params,
+		btpParams:   btpParams,
+		Rlk:         evk.Rlk,
+		Gks:         evk.Gks,
+		Evk:         evk.Evk,
+		evaluator:   eval,
+		BTS:         bts,
+		Slots:       params.MaxSlots(),
+		IsBTS:       isBTS,
+		fingerprint: fp,
+	}
+	e.backend = lattigoBackend{s: e}
+	return e, nil
+}
+
+// Evaluator returns the CKKS evaluator of the Server, or nil if it is a simulation.
+func (e *Server) Evaluator() *ckks.Evaluator { return e.evaluator }
+func (e *Server) Params() ckks.Parameters    { return e.params }
+
+// Backend returns the backend evaluating the primitive operations of the Server.
+func (e *Server) Backend() Backend { return e.backend }
+
+// Fingerprint returns the fingerprint of the server parameters, which is written in front of serialized HEData.
+func (e *Server) Fingerprint() Fingerprint { return e.fingerprint }
+
+// result sets the metadata kept by the Server on an HEData computed by the backend. It records the
+// parameter fingerprint, so that the result can be serialized.
+func (e *Server) result(d *HEData, size int, dirty bool) *HEData {
+	d.size = size
+	d.dirty = dirty
+	d.fingerprint = e.fingerprint
+	return d
+}
+
+// BootstrapCount returns the number of ciphertexts bootstrapped since the Server was built
+// or since the last call to ResetBootstrapCount.
+func (e *Server) BootstrapCount() int { return int(e.bootstraps.Load()) }
+
+// ResetBootstrapCount sets the bootstrap counter to zero. Calling it before a statistic
+// and BootstrapCount after gives the #BTS of that statistic.
+func (e *Server) ResetBootstrapCount() { e.bootstraps.Store(0) }
+
+// ensureLevel returns ct if it has at least depth levels left or if AutoBootstrap is disabled,
+// and ct bootstrapped otherwise.
+func (e *Server) ensureLevel(ct *HEData, depth int) (*HEData, error) {
+	if !e.AutoBootstrap || ct.Level() >= depth {
+		return ct, nil
+	}
+	if !e.IsBTS {
+		return nil, fmt.Errorf("depth %d is required but only %d levels are left and bootstrapping is disabled", depth, ct.Level())
+	}
+	if depth > e.params.MaxLevel() {
+		return nil, fmt.Errorf("depth %d exceeds the maximum level %d", depth, e.params.MaxLevel())
+	}
+	return e.DoBootstrap(ct, depth)
+}
+
+func (e *Server) DoBootstrap(ctxt *HEData, level int) (*HEData, error) {
+	if !e.IsBTS {
+		return nil, fmt.Errorf("The parameter does not support bootstrapping.")
+	}
+	if ctxt.Level() >= level {
+		return ctxt, nil
+	}
+	result, err := e.backend.Bootstrap(ctxt)
+	if err != nil {
+		return nil, err
+	}
+	e.bootstraps.Add(int64(ctxt.NumCiphertexts()))
+	return e.result(result, ctxt.Size(), ctxt.DirtyPadding()), nil
+}
+
+// doBootstrap is DoBootstrap for the ...Ctx methods: it returns the error of ctx if it is done
+// before an actual bootstrapping, and reports a StageBootstrap event after it.
+func (e *Server) doBootstrap(ctx context.Context, ctxt *HEData, level int) (*HEData, error) {
+	if !e.IsBTS || ctxt.Level() >= level {
+		return e.DoBootstrap(ctxt, level)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	res, err := e.DoBootstrap(ctxt, level)
+	if err != nil {
+		return nil, err
+	}
+	return res, e.checkpoint(ctx, ProgressEvent{Stage: StageBootstrap, Level: res.Level()})
+}
+
+// evalPoly evaluates poly on every slot of ct. If AutoBootstrap is set, ct is bootstrapped first
+// when it has less than poly.Depth() levels left. A polynomial does not vanish at zero in general,
+// so the padding of the result is dirty.
+func (e *Server) evalPoly(ct *HEData, poly bignum.Polynomial) (*HEData, error) {
+	ct, err := e.ensureLevel(ct, poly.Depth())
+	if err != nil {
+		return nil, fmt.Errorf("bootstrap: %w", err)
+	}
+	result, err := e.backend.EvalPoly(ct, poly)
+	if err != nil {
+		return nil, err
+	}
+	return e.result(result, ct.Size(), true), nil
+}
+
+// evalComposite evaluates the composite polynomial polys on every slot of ct. Its bootstraps are
+// counted and reported as StageSign events, and it stops with the error of ctx once ctx is done.
+func (e *Server) evalComposite(ctx context.Context, ct *HEData, polys []bignum.Polynomial) (*HEData, error) {
+	if !e.IsBTS {
+		return nil, fmt.Errorf("composite polynomial evaluation requires bootstrapping")
+	}
+	n := 0
+	result, err := e.backend.EvalComposite(ct, polys, func(level int) error {
+		e.bootstraps.Add(1)
+		n++
+		return e.checkpoint(ctx, ProgressEvent{Stage: StageSign, Iteration: n, Level: level})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e.result(result, ct.Size(), true), nil
+}
+
+// replicate returns num copies of the first ciphertext of ct, holding size slots.
+func (e *Server) replicate(ct *HEData, num, size int, dirty bool) (*HEData, error) {
+	result, err := e.backend.Replicate(ct, num)
+	if err != nil {
+		return nil, err
+	}
+	return e.result(result, size, dirty), nil
+}
@@ -1,6 +1,9 @@
 package engine
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"math/big"
 
 	"github.com/tuneinsight/lattigo/v6/circuits/ckks/minimax"
@@ -67,19 +70,25 @@ func (eval Evaluator) Sign(op0 *rlwe.Ciphertext) (sign *rlwe.Ciphertext, err err
 // Step evaluates f(x) = 1 if x > 0, 0 if x < 0, else 0.5 (i.e. (sign+1)/2).
 // This will ensure that step.Scale = params.DefaultScale().
 func (eval Evaluator) Step(op0 *rlwe.Ciphertext) (step *rlwe.Ciphertext, err error) {
+	return eval.Evaluate(op0, stepPolynomial(eval.MinimaxCompositeSignPolynomial))
+}
+
+// stepPolynomial returns the composite polynomial (sign+1)/2 of the composite polynomial sign,
+// obtained by folding the affine map into its last polynomial.
+func stepPolynomial(sign minimax.Polynomial) minimax.Polynomial {
 
-	n := len(eval.MinimaxCompositeSignPolynomial)
+	n := len(sign)
 
 	stepPoly := make([]bignum.Polynomial, n)
 
 	for i := 0; i < n; i++ {
-		stepPoly[i] = eval.MinimaxCompositeSignPolynomial[i]
+		stepPoly[i] = sign[i]
 	}
 
 	half := new(big.Float).SetFloat64(0.5)
 
 	// (x+1)/2
-	lastPoly := eval.MinimaxCompositeSignPolynomial[n-1].Clone()
+	lastPoly := sign[n-1].Clone()
 	for i := range lastPoly.Coeffs {
 		lastPoly.Coeffs[i][0].Mul(lastPoly.Coeffs[i][0], half)
 	}
@@ -87,7 +96,170 @@ func (eval Evaluator) Step(op0 *rlwe.Ciphertext) (step *rlwe.Ciphertext, err err
 
 	stepPoly[n-1] = lastPoly
 
-	return eval.Evaluate(op0, stepPoly)
+	return stepPoly
+}
+
+// ComparisonEvaluator returns the comparison Evaluator of the Server, which is built at the first call.
+// Its MinimaxCompositeSignPolynomial is the one evaluated by Sign, Step, Greater, LessThanConst, Abs and
+// the statistics built on them, and may be replaced before they are called. Its minimax evaluator, which
+// evaluates raw ciphertexts, is nil for a simulated Server and when bootstrapping is disabled.
+func (e *Server) ComparisonEvaluator() *Evaluator {
+	e.cmpOnce.Do(func() {
+		var eval *minimax.Evaluator
+		if e.evaluator != nil && e.BTS != nil {
+			eval = minimax.NewEvaluator(e.params, e.evaluator, e.BTS)
+		}
+		e.cmp = NewEvaluator(e.params, eval)
+	})
+	return e.cmp
+}
+
+// Sign returns sign(x) on every slot x of ct: 1 if x > 0, -1 if x < 0, else 0.
+// The values of ct must lie in [-B, B]; they are divided by B before the composite polynomial is
+// evaluated, with bootstraps between its stages. Bootstrapping is required.
+func (e *Server) Sign(ct *HEData, B float64) (*HEData, error) {
+	return e.SignCtx(context.Background(), ct, B)
+}
+
+// SignCtx is Sign returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps. Its progress is reported to e.Progress.
+func (e *Server) SignCtx(ctx context.Context, ct *HEData, B float64) (*HEData, error) {
+	x, err := e.comparisonInput(ctx, ct, 1, B)
+	if err != nil {
+		return nil, err
+	}
+	return e.sign(ctx, x)
+}
+
+// Step returns step(x) on every slot x of ct: 1 if x > 0, 0 if x < 0, else 0.5.
+// The values of ct must lie in [-B, B]; see Sign.
+func (e *Server) Step(ct *HEData, B float64) (*HEData, error) {
+	return e.StepCtx(context.Background(), ct, B)
+}
+
+// StepCtx is Step returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps. Its progress is reported to e.Progress.
+func (e *Server) StepCtx(ctx context.Context, ct *HEData, B float64) (*HEData, error) {
+	x, err := e.comparisonInput(ctx, ct, 1, B)
+	if err != nil {
+		return nil, err
+	}
+	return e.step(ctx, x)
+}
+
+// Greater returns 1 on the slots where ct1 is larger than ct2, 0 where it is smaller and 0.5 where they are equal.
+// The values of ct1 and ct2 must lie in [-B, B], so that their difference divided by 2B lies in [-1, 1].
+func (e *Server) Greater(ct1, ct2 *HEData, B float64) (*HEData, error) {
+	return e.GreaterCtx(context.Background(), ct1, ct2, B)
+}
+
+// GreaterCtx is Greater returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps. Its progress is reported to e.Progress.
+func (e *Server) GreaterCtx(ctx context.Context, ct1, ct2 *HEData, B float64) (*HEData, error) {
+	diff, err := e.Sub(ct1, ct2)
+	if err != nil {
+		return nil, fmt.Errorf("ct1 - ct2: %w", err)
+	}
+	x, err := e.comparisonInput(ctx, diff, 0.5, B)
+	if err != nil {
+		return nil, err
+	}
+	return e.step(ctx, x)
+}
+
+// LessThanConst returns 1 on the slots of ct smaller than c, 0 on those larger and 0.5 on those equal.
+// The values of ct and c must lie in [-B, B], so that their difference divided by 2B lies in [-1, 1].
+func (e *Server) LessThanConst(ct *HEData, c, B float64) (*HEData, error) {
+	return e.LessThanConstCtx(context.Background(), ct, c, B)
+}
+
+// LessThanConstCtx is LessThanConst returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps. Its progress is reported to e.Progress.
+func (e *Server) LessThanConstCtx(ctx context.Context, ct *HEData, c, B float64) (*HEData, error) {
+	diff, err := e.SubConst(ct, c)
+	if err != nil {
+		return nil, fmt.Errorf("ct - c: %w", err)
+	}
+	// step((c - x)/2B) is 1 where x < c
+	x, err := e.comparisonInput(ctx, diff, -0.5, B)
+	if err != nil {
+		return nil, err
+	}
+	return e.step(ctx, x)
+}
+
+// GreaterThanConst returns 1 on the slots of ct larger than c, 0 on those smaller and 0.5 on those equal.
+// The values of ct and c must lie in [-B, B], so that their difference divided by 2B lies in [-1, 1].
+func (e *Server) GreaterThanConst(ct *HEData, c, B float64) (*HEData, error) {
+	return e.GreaterThanConstCtx(context.Background(), ct, c, B)
+}
+
+// GreaterThanConstCtx is GreaterThanConst returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps. Its progress is reported to e.Progress.
+func (e *Server) GreaterThanConstCtx(ctx context.Context, ct *HEData, c, B float64) (*HEData, error) {
+	diff, err := e.SubConst(ct, c)
+	if err != nil {
+		return nil, fmt.Errorf("ct - c: %w", err)
+	}
+	x, err := e.comparisonInput(ctx, diff, 0.5, B)
+	if err != nil {
+		return nil, err
+	}
+	return e.step(ctx, x)
+}
+
+// Abs returns |x| = x·sign(x) on every slot x of ct.
+// The values of ct must lie in [-B, B]; see Sign.
+func (e *Server) Abs(ct *HEData, B float64) (*HEData, error) {
+	return e.AbsCtx(context.Background(), ct, B)
+}
+
+// AbsCtx is Abs returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps. Its progress is reported to e.Progress.
+func (e *Server) AbsCtx(ctx context.Context, ct *HEData, B float64) (*HEData, error) {
+	sign, err := e.SignCtx(ctx, ct, B)
+	if err != nil {
+		return nil, fmt.Errorf("sign: %w", err)
+	}
+	if sign, err = e.doBootstrap(ctx, sign, 1); err != nil {
+		return nil, fmt.Errorf("bootstrap sign: %w", err)
+	}
+	if ct, err = e.doBootstrap(ctx, ct, 1); err != nil {
+		return nil, fmt.Errorf("bootstrap input: %w", err)
+	}
+	return e.Mult(ct, sign)
+}
+
+// comparisonInput multiplies ct by c/B, which brings its values into [-1, 1],
+// after bootstrapping it if no level is left for the multiplication.
+func (e *Server) comparisonInput(ctx context.Context, ct *HEData, c, B float64) (*HEData, error) {
+	if !e.IsBTS {
+		return nil, fmt.Errorf("comparisons require bootstrapping")
+	}
+	if !(B > 0) || math.IsInf(B, 1) {
+		return nil, fmt.Errorf("bound B must be positive and finite: %v", B)
+	}
+	factor := c / B
+	ct, err := e.doBootstrap(ctx, ct, 1)
+	if err != nil {
+		return nil, fmt.Errorf("bootstrap: %w", err)
+	}
+	if ct, err = e.MultConst(ct, factor); err != nil {
+		return nil, fmt.Errorf("scale into [-1, 1]: %w", err)
+	}
+	return ct, nil
+}
+
+// sign evaluates on every slot of ct, which must lie in [-1, 1], the composite sign polynomial
+// of the comparison evaluator.
+func (e *Server) sign(ctx context.Context, ct *HEData) (*HEData, error) {
+	return e.evalComposite(ctx, ct, e.ComparisonEvaluator().MinimaxCompositeSignPolynomial)
+}
+
+// step evaluates on every slot of ct, which must lie in [-1, 1], the step function (sign+1)/2
+// of the composite sign polynomial of the comparison evaluator.
+func (e *Server) step(ctx context.Context, ct *HEData) (*HEData, error) {
+	return e.evalComposite(ctx, ct, stepPolynomial(e.ComparisonEvaluator().MinimaxCompositeSignPolynomial))
 }
 
 // Max returns the smooth maximum of op0 and op1, which is defined as: op0 * x + op1 * (1-x) where x = step(diff = op0-op1).
@@ -0,0 +1,60 @@
+package engine_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hm-choi/pp-stat/engine"
+)
+
+// step is the plaintext reference of Step: 1 if x > 0, 0 if x < 0.
+func step(x float64) float64 {
+	if x > 0 {
+		return 1
+	}
+	return 0
+}
+
+func TestSimComparisons(t *testing.T) {
+	e := newSimEngine(t, true)
+	const B, c = 10.0, 1.5
+	// Every value of a, a - b and a - c is at least 0.5 away from 0, outside the transition of the sign polynomial.
+	a := []float64{-9.5, -4, -0.5, 0.5, 1, 2, 6, 9.5}
+	b := []float64{3, -6, 0.5, -0.5, 4, 1, 9, -9.5}
+	cta, err := e.Encrypt(a)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	ctb, err := e.Encrypt(b)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		eval func(B float64) (*engine.HEData, error)
+		want func(i int) float64
+	}{
+		{"Sign", func(B float64) (*engine.HEData, error) { return e.Sign(cta, B) }, func(i int) float64 { return 2*step(a[i]) - 1 }},
+		{"Step", func(B float64) (*engine.HEData, error) { return e.Step(cta, B) }, func(i int) float64 { return step(a[i]) }},
+		{"Abs", func(B float64) (*engine.HEData, error) { return e.Abs(cta, B) }, func(i int) float64 { return math.Abs(a[i]) }},
+		{"Greater", func(B float64) (*engine.HEData, error) { return e.Greater(cta, ctb, B) }, func(i int) float64 { return step(a[i] - b[i]) }},
+		{"GreaterThanConst", func(B float64) (*engine.HEData, error) { return e.GreaterThanConst(cta, c, B) }, func(i int) float64 { return step(a[i] - c) }},
+		{"LessThanConst", func(B float64) (*engine.HEData, error) { return e.LessThanConst(cta, c, B) }, func(i int) float64 { return step(c - a[i]) }},
+	} {
+		d, err := tc.eval(B)
+		got := decryptSim(t, e, d, err)
+		for i := range a {
+			if want := tc.want(i); math.Abs(got[i]-want) > 1e-2 {
+				t.Errorf("%s: value %d: got %v, want %v", tc.name, i, got[i], want)
+			}
+		}
+
+		// A negative bound would flip every comparison.
+		for _, bad := range []float64{-B, 0, math.Inf(1), math.NaN()} {
+			if _, err := tc.eval(bad); err == nil {
+				t.Errorf("%s accepted the bound %v", tc.name, bad)
+			}
+		}
+	}
+}
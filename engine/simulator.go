@@ -0,0 +1,430 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// SimNoise is the standard deviation of the Gaussian error a Simulator adds to every slot to mimic
+// the error of CKKS. The zero value simulates exact arithmetic.
+type SimNoise struct {
+	Fresh     float64 // added by the encryption
+	Rescale   float64 // added by every operation consuming levels
+	Bootstrap float64 // added by every bootstrapping
+	Seed      uint64  // seed of the noise generator
+}
+
+// simCiphertext is the simulated counterpart of a ciphertext: its slots in clear, with the
+// level and the CKKS scale the ciphertext would have.
+type simCiphertext struct {
+	values []float64
+	level  int
+	scale  float64
+}
+
+func (ct *simCiphertext) copy() *simCiphertext {
+	return &simCiphertext{values: append([]float64(nil), ct.values...), level: ct.level, scale: ct.scale}
+}
+
+// Simulator is a Backend evaluating the circuits of the Server on plaintext slots, which makes a run
+// orders of magnitude faster.
+//
+// It consumes levels as the Lattigo backend does and fails where the latter would run out of levels,
+// so that the depth of a circuit can be checked before generating real keys. The scales follow the
+// rescalings by the moduli of the parameters, and the polynomials are evaluated on the slots, so that
+// the approximation error of the circuits is reproduced. The CKKS error itself is replaced by the
+// Gaussian noise of SimNoise.
+type Simulator struct {
+	params ckks.Parameters
+	noise  SimNoise
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewSimulator returns a Simulator for the given parameters.
+func NewSimulator(params ckks.Parameters, noise SimNoise) *Simulator {
+	return &Simulator{
+		params: params,
+		noise:  noise,
+		rng:    rand.New(rand.NewPCG(noise.Seed, noise.Seed)),
+	}
+}
+
+// NewSimServer returns a Server evaluating the statistics with sim.
+// The Evaluator and the bootstrapping evaluator of the returned Server are nil.
+func NewSimServer(isBTS bool, sim *Simulator) (*Server, error) {
+	if err := checkParams(isBTS, sim.params); err != nil {
+		return nil, err
+	}
+	fp, err := ParamsFingerprint(sim.params)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		params:      sim.params,
+		Slots:       sim.params.MaxSlots(),
+		IsBTS:       isBTS,
+		backend:     sim,
+		fingerprint: fp,
+	}, nil
+}
+
+// NewSimHEEngine returns an HEEngine whose Client encrypts to simulated data and whose Server
+// evaluates it with a Simulator. No key is generated.
+func NewSimHEEngine(isBTS bool, params ckks.Parameters, noise SimNoise) (*HEEngine, error) {
+	sim := NewSimulator(params, noise)
+	server, err := NewSimServer(isBTS, sim)
+	if err != nil {
+		return nil, err
+	}
+	client := &Client{params: params, slots: params.MaxSlots(), isBTS: isBTS, fingerprint: server.fingerprint, sim: sim}
+	return &HEEngine{Client: client, Server: server}, nil
+}
+
+// Encrypt returns the simulated encryption of input at the maximum level and the default scale.
+func (s *Simulator) Encrypt(input []float64) *HEData {
+	slots := s.params.MaxSlots()
+	cts := make([]*simCiphertext, (len(input)+slots-1)/slots)
+	for i := range cts {
+		values := make([]float64, slots)
+		copy(values, input[i*slots:min((i+1)*slots, len(input))])
+		cts[i] = &simCiphertext{values: values, level: s.params.MaxLevel(), scale: s.defaultScale()}
+		s.addNoise(cts[i], s.noise.Fresh)
+	}
+	return s.data(cts, &HEData{size: len(input), scale: 60.0})
+}
+
+// decodeSim returns the slots of every simulated ciphertext of d, one after another.
+func decodeSim(d *HEData) []float64 {
+	output := []float64{}
+	for _, ct := range d.sim {
+		output = append(output, ct.values...)
+	}
+	return output
+}
+
+func (s *Simulator) defaultScale() float64 {
+	return s.params.DefaultScale().Float64()
+}
+
+// data returns an HEData holding cts, with the size and scale of like and the level of the ciphertexts.
+func (s *Simulator) data(cts []*simCiphertext, like *HEData) *HEData {
+	d := NewHEData(nil, like.Size(), cts[0].level, like.Scale())
+	d.sim = cts
+	return d
+}
+
+func (s *Simulator) addNoise(ct *simCiphertext, sigma float64) {
+	if sigma == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range ct.values {
+		ct.values[i] += sigma * s.rng.NormFloat64()
+	}
+}
+
+// alignScales returns the values of ct0 and ct1 brought to the larger of their scales, together with
+// that scale. As in Lattigo, the operand of smaller scale is multiplied by the integer part of the
+// ratio of the scales, which leaves an error when the ratio is not an integer.
+func alignScales(ct0, ct1 *simCiphertext) (v0, v1 []float64, scale float64) {
+	v0, v1 = ct0.values, ct1.values
+	switch {
+	case ct0.scale > ct1.scale:
+		v1 = scaleValues(v1, math.Floor(ct0.scale/ct1.scale)*ct1.scale/ct0.scale)
+		return v0, v1, ct0.scale
+	case ct0.scale < ct1.scale:
+		v0 = scaleValues(v0, math.Floor(ct1.scale/ct0.scale)*ct0.scale/ct1.scale)
+		return v0, v1, ct1.scale
+	}
+	return v0, v1, ct0.scale
+}
+
+func scaleValues(values []float64, factor float64) []float64 {
+	out := make([]float64, len(values))
+	for i, v := range values {
+		out[i] = v * factor
+	}
+	return out
+}
+
+func (s *Simulator) Add(a, b *HEData) (*HEData, error) {
+	return s.binary(a, b, func(x, y float64) float64 { return x + y })
+}
+
+func (s *Simulator) Sub(a, b *HEData) (*HEData, error) {
+	return s.binary(a, b, func(x, y float64) float64 { return x - y })
+}
+
+func (s *Simulator) binary(a, b *HEData, op func(x, y float64) float64) (*HEData, error) {
+	cts := make([]*simCiphertext, max(len(a.sim), len(b.sim)))
+	for i := range cts {
+		switch {
+		case i >= len(a.sim):
+			cts[i] = b.sim[i]
+		case i >= len(b.sim):
+			cts[i] = a.sim[i]
+		default:
+			v0, v1, scale := alignScales(a.sim[i], b.sim[i])
+			values := make([]float64, len(v0))
+			for j := range values {
+				values[j] = op(v0[j], v1[j])
+			}
+			cts[i] = &simCiphertext{values: values, level: min(a.sim[i].level, b.sim[i].level), scale: scale}
+		}
+	}
+	return s.data(cts, a), nil
+}
+
+func (s *Simulator) AddConst(a *HEData, con float64) (*HEData, error) {
+	return s.unary(a, func(i int, ct *simCiphertext) (*simCiphertext, error) {
+		res := ct.copy()
+		for j := range res.values {
+			res.values[j] += con
+		}
+		return res, nil
+	})
+}
+
+// unary applies op to every ciphertext of a.
+func (s *Simulator) unary(a *HEData, op func(i int, ct *simCiphertext) (*simCiphertext, error)) (*HEData, error) {
+	cts := make([]*simCiphertext, len(a.sim))
+	for i, ct := range a.sim {
+		res, err := op(i, ct)
+		if err != nil {
+			return nil, fmt.Errorf("ciphertext %d: %w", i, err)
+		}
+		cts[i] = res
+	}
+	return s.data(cts, a), nil
+}
+
+// rescale consumes one level of ct and divides its scale by the modulus of that level.
+func (s *Simulator) rescale(ct *simCiphertext) error {
+	if ct.level < 1 {
+		return fmt.Errorf("cannot rescale: level is too low")
+	}
+	ct.scale /= float64(s.params.Q()[ct.level])
+	ct.level--
+	s.addNoise(ct, s.noise.Rescale)
+	return nil
+}
+
+func (s *Simulator) Mult(a, b *HEData) (*HEData, error) {
+	cts := make([]*simCiphertext, min(len(a.sim), len(b.sim)))
+	for i := range cts {
+		ct0, ct1 := a.sim[i], b.sim[i]
+		res := &simCiphertext{values: make([]float64, len(ct0.values)), level: min(ct0.level, ct1.level), scale: ct0.scale * ct1.scale}
+		for j := range res.values {
+			res.values[j] = ct0.values[j] * ct1.values[j]
+		}
+		if err := s.rescale(res); err != nil {
+			return nil, fmt.Errorf("ciphertext %d: %w", i, err)
+		}
+		cts[i] = res
+	}
+	return s.data(cts, a), nil
+}
+
+func (s *Simulator) MultInt(a *HEData, con int) (*HEData, error) {
+	return s.unary(a, func(i int, ct *simCiphertext) (*simCiphertext, error) {
+		return &simCiphertext{values: scaleValues(ct.values, float64(con)), level: ct.level, scale: ct.scale}, nil
+	})
+}
+
+// MultMask encodes the mask at the scale of the modulus of the current level, as Lattigo does,
+// so the rescaling leaves the scale unchanged.
+func (s *Simulator) MultMask(a *HEData, con float64) (*HEData, error) {
+	slots := s.params.MaxSlots()
+	return s.unary(a, func(i int, ct *simCiphertext) (*simCiphertext, error) {
+		res := &simCiphertext{values: make([]float64, len(ct.values)), level: ct.level, scale: ct.scale * float64(s.params.Q()[ct.level])}
+		for j := 0; j < min(max(a.Size()-i*slots, 0), slots); j++ {
+			res.values[j] = ct.values[j] * con
+		}
+		if err := s.rescale(res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	})
+}
+
+// MultVec encodes the vectors at the scale of the modulus of the current level, as Lattigo does.
+func (s *Simulator) MultVec(a *HEData, vecs [][]float64) (*HEData, error) {
+	return s.unary(a, func(i int, ct *simCiphertext) (*simCiphertext, error) {
+		res := &simCiphertext{values: make([]float64, len(ct.values)), level: ct.level, scale: ct.scale * float64(s.params.Q()[ct.level])}
+		for j, v := range vecs[i] {
+			res.values[j] = ct.values[j] * v
+		}
+		if err := s.rescale(res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	})
+}
+
+func (s *Simulator) AddVec(a *HEData, vecs [][]float64) (*HEData, error) {
+	return s.unary(a, func(i int, ct *simCiphertext) (*simCiphertext, error) {
+		res := ct.copy()
+		for j, v := range vecs[i] {
+			res.values[j] += v
+		}
+		return res, nil
+	})
+}
+
+func (s *Simulator) Rotate(a *HEData, k int) (*HEData, error) {
+	slots := s.params.MaxSlots()
+	k = ((k % slots) + slots) % slots
+	return s.unary(a, func(i int, ct *simCiphertext) (*simCiphertext, error) {
+		res := &simCiphertext{values: make([]float64, slots), level: ct.level, scale: ct.scale}
+		for j := range res.values {
+			res.values[j] = ct.values[(j+k)%slots]
+		}
+		return res, nil
+	})
+}
+
+func (s *Simulator) Sum(a *HEData) (*HEData, error) {
+	sum := 0.0
+	level := a.sim[0].level
+	for _, ct := range a.sim {
+		for _, v := range ct.values {
+			sum += v
+		}
+		level = min(level, ct.level)
+	}
+
+	cts := make([]*simCiphertext, len(a.sim))
+	for i := range cts {
+		values := make([]float64, s.params.MaxSlots())
+		for j := range values {
+			values[j] = sum
+		}
+		cts[i] = &simCiphertext{values: values, level: level, scale: a.sim[0].scale}
+	}
+	return s.data(cts, a), nil
+}
+
+func (s *Simulator) Replicate(a *HEData, n int) (*HEData, error) {
+	cts := make([]*simCiphertext, n)
+	for i := range cts {
+		cts[i] = a.sim[0].copy()
+	}
+	return s.data(cts, a), nil
+}
+
+// Bootstrap reproduces the relabeling of the scale to twice the default scale done before the
+// bootstrapping: a ciphertext whose scale differs from the default one has its values multiplied
+// by the ratio of the scales.
+func (s *Simulator) Bootstrap(a *HEData) (*HEData, error) {
+	return s.unary(a, func(i int, ct *simCiphertext) (*simCiphertext, error) {
+		res := &simCiphertext{values: scaleValues(ct.values, ct.scale/s.defaultScale())}
+		s.bootstrap(res)
+		return res, nil
+	})
+}
+
+// bootstrap refreshes ct to the maximum level and the default scale, keeping its values.
+func (s *Simulator) bootstrap(ct *simCiphertext) {
+	ct.level = s.params.MaxLevel()
+	ct.scale = s.defaultScale()
+	s.addNoise(ct, s.noise.Bootstrap)
+}
+
+// EvalPoly reproduces the relabeling of the result to the scale of the input: if the latter differs
+// from the default scale, the values are multiplied by the ratio of the scales.
+func (s *Simulator) EvalPoly(a *HEData, poly bignum.Polynomial) (*HEData, error) {
+	p := newSimPoly(poly)
+	return s.unary(a, func(i int, ct *simCiphertext) (*simCiphertext, error) {
+		if ct.level < p.depth {
+			return nil, fmt.Errorf("cannot evaluate a polynomial of depth %d at level %d", p.depth, ct.level)
+		}
+		res := &simCiphertext{values: make([]float64, len(ct.values)), level: ct.level - p.depth, scale: ct.scale}
+		for j, v := range ct.values {
+			res.values[j] = p.eval(v) * s.defaultScale() / ct.scale
+		}
+		s.addNoise(res, s.noise.Rescale)
+		return res, nil
+	})
+}
+
+// EvalComposite bootstraps before a polynomial when the level left is smaller than its depth plus
+// one, as the minimax evaluator of Lattigo does.
+func (s *Simulator) EvalComposite(a *HEData, polys []bignum.Polynomial, onBootstrap func(level int) error) (*HEData, error) {
+	ps := make([]simPoly, len(polys))
+	for i := range polys {
+		ps[i] = newSimPoly(polys[i])
+		if ps[i].depth+1 > s.params.MaxLevel() {
+			return nil, fmt.Errorf("polynomial %d of depth %d cannot be evaluated after a bootstrapping to level %d", i, ps[i].depth, s.params.MaxLevel())
+		}
+	}
+
+	return s.unary(a, func(i int, ct *simCiphertext) (*simCiphertext, error) {
+		res := ct.copy()
+		for _, p := range ps {
+			if res.level < p.depth+1 {
+				s.bootstrap(res)
+				if onBootstrap != nil {
+					if err := onBootstrap(res.level); err != nil {
+						return nil, err
+					}
+				}
+			}
+			for j, v := range res.values {
+				res.values[j] = p.eval(v)
+			}
+			res.level -= p.depth
+			res.scale = s.defaultScale()
+			s.addNoise(res, s.noise.Rescale)
+		}
+		return res, nil
+	})
+}
+
+// simPoly is a bignum.Polynomial with float64 coefficients.
+type simPoly struct {
+	coeffs    []float64
+	chebyshev bool
+	depth     int
+}
+
+func newSimPoly(poly bignum.Polynomial) simPoly {
+	p := simPoly{coeffs: make([]float64, len(poly.Coeffs)), chebyshev: poly.Basis == bignum.Chebyshev, depth: poly.Depth()}
+	for i, c := range poly.Coeffs {
+		if c != nil && c[0] != nil {
+			p.coeffs[i], _ = c[0].Float64()
+		}
+	}
+	return p
+}
+
+// eval evaluates the polynomial at x, with Horner's method in the monomial basis and
+// with Clenshaw's algorithm in the Chebyshev basis. As with the polynomial evaluator of Lattigo,
+// a Chebyshev polynomial on [A, B] is evaluated on x already mapped into [-1, 1] by the caller.
+func (p simPoly) eval(x float64) float64 {
+	n := len(p.coeffs)
+	if n == 0 {
+		return 0
+	}
+	if !p.chebyshev {
+		y := 0.0
+		for i := n - 1; i >= 0; i-- {
+			y = y*x + p.coeffs[i]
+		}
+		return y
+	}
+
+	t := x
+	b1, b2 := 0.0, 0.0
+	for i := n - 1; i >= 1; i-- {
+		b1, b2 = p.coeffs[i]+2*t*b1-b2, b1
+	}
+	return p.coeffs[0] + t*b1 - b2
+}
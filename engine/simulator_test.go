@@ -0,0 +1,119 @@
+package engine_test
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/hm-choi/pp-stat/config"
+	"github.com/hm-choi/pp-stat/engine"
+	"github.com/hm-choi/pp-stat/utils"
+)
+
+// newSimEngine returns a simulated engine with the Test parameters, which runs the full circuits
+// without generating any key.
+func newSimEngine(t *testing.T, isBTS bool) *engine.HEEngine {
+	t.Helper()
+	_, params, _, err := config.Test.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	e, err := engine.NewSimHEEngine(isBTS, params, engine.SimNoise{})
+	if err != nil {
+		t.Fatalf("NewSimHEEngine: %v", err)
+	}
+	return e
+}
+
+// uniform returns n values drawn uniformly in [0, B) with a fixed seed.
+func uniform(n int, B float64, seed int64) []float64 {
+	r := rand.New(rand.NewSource(seed))
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = B * r.Float64()
+	}
+	return values
+}
+
+func decryptSim(t *testing.T, e *engine.HEEngine, d *engine.HEData, err error) []float64 {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := e.Decrypt(d)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	return values
+}
+
+func TestSimStatistics(t *testing.T) {
+	e := newSimEngine(t, true)
+	const B = 20.0
+	values := uniform(1000, B, 1)
+	ct, err := e.Encrypt(values)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	for _, opts := range []engine.MomentOptions{{}, {Estimator: engine.Sample}, {Raw: true}} {
+		_, _, skew := utils.Skewness(values, opts)
+		_, _, kurt := utils.Kurtosis(values, opts)
+		_, _, cv := utils.CoeffVar(values, opts)
+		for _, tc := range []struct {
+			name string
+			eval func() (*engine.HEData, error)
+			want float64
+		}{
+			{"Variance", func() (*engine.HEData, error) { return e.Variance(ct, opts) }, utils.Variance(values, opts)},
+			{"Skewness", func() (*engine.HEData, error) { return e.Skewness(ct, B, opts) }, skew},
+			{"Kurtosis", func() (*engine.HEData, error) { return e.Kurtosis(ct, B, opts) }, kurt},
+			{"CoeffVar", func() (*engine.HEData, error) { return e.CoeffVar(ct, B, opts) }, cv},
+		} {
+			d, err := tc.eval()
+			got := decryptSim(t, e, d, err)
+			if diff := math.Abs(got[0] - tc.want); diff > 1e-2*math.Max(1, math.Abs(tc.want)) {
+				t.Errorf("%s %+v: got %v, want %v", tc.name, opts, got[0], tc.want)
+			}
+		}
+	}
+}
+
+func TestSimZScoreNorm(t *testing.T) {
+	e := newSimEngine(t, true)
+	const B = 100.0
+	values := uniform(1000, B, 2)
+	ct, err := e.Encrypt(values)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	d, err := e.ZScoreNorm(ct, B)
+	got := decryptSim(t, e, d, err)
+	for i, want := range utils.ZScoreNorm(values) {
+		if math.Abs(got[i]-want) > 1e-2 {
+			t.Fatalf("value %d: got %v, want %v", i, got[i], want)
+		}
+	}
+}
+
+// TestSimOutOfDepth checks that circuits deeper than the levels left without bootstrapping
+// fail with an error rather than a panic or a wrong result.
+func TestSimOutOfDepth(t *testing.T) {
+	e := newSimEngine(t, false)
+	const B = 20.0
+	ct, err := e.Encrypt(uniform(100, B, 3))
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	for name, eval := range map[string]func() (*engine.HEData, error){
+		"ZScoreNorm":    func() (*engine.HEData, error) { return e.ZScoreNorm(ct, B) },
+		"Skewness":      func() (*engine.HEData, error) { return e.Skewness(ct, B, engine.MomentOptions{}) },
+		"Kurtosis":      func() (*engine.HEData, error) { return e.Kurtosis(ct, B, engine.MomentOptions{}) },
+		"CryptoInvSqrt": func() (*engine.HEData, error) { return e.CryptoInvSqrt(ct, B) },
+	} {
+		if _, err := eval(); err == nil || !strings.Contains(err.Error(), "level") {
+			t.Errorf("%s without bootstrapping: got %v, want an error about the level", name, err)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// TTestResult is the encrypted result of TTest. Its values are replicated in every slot like Sum.
+type TTestResult struct {
+	// T is the t statistic (μ1 - μ2)/SE.
+	T *HEData
+	// DF is the Welch–Satterthwaite approximation of the degrees of freedom of Welch's test. It is nil for
+	// Student's test, whose n1 + n2 - 2 degrees of freedom do not depend on the values.
+	DF *HEData
+}
+
+// TTest compares the means of the two samples ct1 and ct2 of n1 and n2 values. It is Student's test with the
+// pooled variance ((n1-1)s1² + (n2-1)s2²)/(n1+n2-2), and Welch's test with the standard error sqrt(s1²/n1 + s2²/n2)
+// if welch is set, where s² are the unbiased sample variances.
+//
+// The values of ct1 and ct2 must lie in [-B, B], so that the variances divided by B² lie in [0, 1].
+// The standard error is inverted by the path of CryptoInvSqrt on a weighted mean of these scaled variances,
+// which must not be much smaller than 0.001, and Welch's degrees of freedom by the Newton inverse of CryptoInv.
+// Bootstrapping is required.
+func (e *Server) TTest(ct1, ct2 *HEData, B float64, welch bool) (*TTestResult, error) {
+	return e.TTestCtx(context.Background(), ct1, ct2, B, welch)
+}
+
+// TTestCtx is TTest returning the error of ctx as soon as it is done, which is checked
+// between two bootstraps or Newton iterations. Its progress is reported to e.Progress.
+func (e *Server) TTestCtx(ctx context.Context, ct1, ct2 *HEData, B float64, welch bool) (*TTestResult, error) {
+	const chebyshevLevel = 10
+
+	if !e.IsBTS {
+		return nil, fmt.Errorf("the t-test requires bootstrapping")
+	}
+	n1, n2 := float64(ct1.Size()), float64(ct2.Size())
+	if n1 < 2 || n2 < 2 {
+		return nil, fmt.Errorf("each sample needs at least two values, got %v and %v", n1, n2)
+	}
+
+	// Step 1: (μ1 - μ2)/B
+	means := make([]*HEData, 2)
+	variances := make([]*HEData, 2)
+	for i, ct := range []*HEData{ct1, ct2} {
+		n := float64(ct.Size())
+		mean, err := meanWithCustomDenom(e, ct, n*B)
+		if err != nil {
+			return nil, fmt.Errorf("mean of ct%d: %w", i+1, err)
+		}
+		if means[i], err = e.scalarOperand(ctx, mean, 2); err != nil {
+			return nil, fmt.Errorf("mean of ct%d: %w", i+1, err)
+		}
+
+		// Step 2: Population variances divided by B²
+		variance, err := varianceWithCustomDenom(e, ct, n*B, n*B*B)
+		if err != nil {
+			return nil, fmt.Errorf("variance of ct%d: %w", i+1, err)
+		}
+		if variances[i], err = e.scalarOperand(ctx, variance, 2); err != nil {
+			return nil, fmt.Errorf("variance of ct%d: %w", i+1, err)
+		}
+	}
+	diff, err := e.Sub(means[0], means[1])
+	if err != nil {
+		return nil, fmt.Errorf("μ1 - μ2: %w", err)
+	}
+
+	// Step 3: SE²/B² = x/c for a weighted mean x of the scaled variances, i.e. 1/SE = sqrt(c)/(B·sqrt(x))
+	w1, w2, c := n1/(n1+n2-2), n2/(n1+n2-2), 1/(1/n1+1/n2)
+	if welch {
+		// s²/n = v/(n-1) for the population variance v
+		c = 1 / (1/(n1-1) + 1/(n2-1))
+		w1, w2 = c/(n1-1), c/(n2-1)
+	}
+	a, err := e.MultConst(variances[0], w1)
+	if err != nil {
+		return nil, fmt.Errorf("weight of ct1: %w", err)
+	}
+	b, err := e.MultConst(variances[1], w2)
+	if err != nil {
+		return nil, fmt.Errorf("weight of ct2: %w", err)
+	}
+	x, err := e.Add(a, b)
+	if err != nil {
+		return nil, fmt.Errorf("weighted variance: %w", err)
+	}
+	if x, err = e.doBootstrap(ctx, x, chebyshevLevel); err != nil {
+		return nil, fmt.Errorf("bootstrap weighted variance: %w", err)
+	}
+
+	// Step 4: t = (μ1 - μ2)/B · sqrt(c) · 1/sqrt(x)
+	invSqrt, err := e.cryptoInvSqrt(ctx, x, 1)
+	if err != nil {
+		return nil, fmt.Errorf("CryptoInvSqrt: %w", err)
+	}
+	if diff, err = e.MultConst(diff, math.Sqrt(c)); err != nil {
+		return nil, fmt.Errorf("scale μ1 - μ2: %w", err)
+	}
+	t, err := e.scalarMult(ctx, diff, invSqrt)
+	if err != nil {
+		return nil, fmt.Errorf("t statistic: %w", err)
+	}
+	result := &TTestResult{}
+	if result.T, err = e.extendOneToMulty(t, ct1.NumCiphertexts(), ct1.Size()); err != nil {
+		return nil, fmt.Errorf("extendOneToMulty: %w", err)
+	}
+	if !welch {
+		return result, nil
+	}
+
+	// Step 5: df = (a+b)²/(a²/(n1-1) + b²/(n2-1)) = m/y for m = min(n1, n2) - 1 and
+	// y = m·(a²/(n1-1) + b²/(n2-1))/x², which lies in [m/(n1+n2-2), 1]
+	m := min(n1, n2) - 1
+	a2, err := e.scalarMult(ctx, a, a)
+	if err != nil {
+		return nil, fmt.Errorf("a²: %w", err)
+	}
+	if a2, err = e.MultConst(a2, m/(n1-1)); err != nil {
+		return nil, fmt.Errorf("scale a²: %w", err)
+	}
+	b2, err := e.scalarMult(ctx, b, b)
+	if err != nil {
+		return nil, fmt.Errorf("b²: %w", err)
+	}
+	if b2, err = e.MultConst(b2, m/(n2-1)); err != nil {
+		return nil, fmt.Errorf("scale b²: %w", err)
+	}
+	y, err := e.Add(a2, b2)
+	if err != nil {
+		return nil, fmt.Errorf("a² + b²: %w", err)
+	}
+	invX, err := e.scalarMult(ctx, invSqrt, invSqrt)
+	if err != nil {
+		return nil, fmt.Errorf("1/x: %w", err)
+	}
+	for range 2 {
+		if y, err = e.scalarMult(ctx, y, invX); err != nil {
+			return nil, fmt.Errorf("divide by x: %w", err)
+		}
+	}
+	invY, err := e.invFraction(ctx, y)
+	if err != nil {
+		return nil, fmt.Errorf("inverse: %w", err)
+	}
+	if invY, err = e.scalarOperand(ctx, invY, 1); err != nil {
+		return nil, fmt.Errorf("inverse: %w", err)
+	}
+	df, err := e.MultConst(invY, m)
+	if err != nil {
+		return nil, fmt.Errorf("degrees of freedom: %w", err)
+	}
+	if result.DF, err = e.extendOneToMulty(df, ct1.NumCiphertexts(), ct1.Size()); err != nil {
+		return nil, fmt.Errorf("extendOneToMulty: %w", err)
+	}
+	return result, nil
+}
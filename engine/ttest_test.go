@@ -0,0 +1,47 @@
+package engine_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hm-choi/pp-stat/utils"
+)
+
+func TestSimTTest(t *testing.T) {
+	e := newSimEngine(t, true)
+	const B = 10.0
+	// Two samples of different sizes and variances, where Student's and Welch's tests differ.
+	x := uniform(120, B, 23)
+	y := uniform(80, 4, 24)
+	for i := range y {
+		y[i] += 4
+	}
+	ct1, err := e.Encrypt(x)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	ct2, err := e.Encrypt(y)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	for _, welch := range []bool{false, true} {
+		wantT, wantDF := utils.TTest(x, y, welch)
+		result, err := e.TTest(ct1, ct2, B, welch)
+		if err != nil {
+			t.Fatalf("TTest(welch = %v): %v", welch, err)
+		}
+		if got := decryptSim(t, e, result.T, nil)[0]; math.Abs(got-wantT) > 1e-3*math.Abs(wantT) {
+			t.Errorf("TTest(welch = %v): t = %v, want %v", welch, got, wantT)
+		}
+		if !welch {
+			if result.DF != nil {
+				t.Errorf("TTest(welch = false): DF is not nil")
+			}
+			continue
+		}
+		if got := decryptSim(t, e, result.DF, nil)[0]; math.Abs(got-wantDF) > 1e-3*wantDF {
+			t.Errorf("TTest(welch = true): df = %v, want %v", got, wantDF)
+		}
+	}
+}
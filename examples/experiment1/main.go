@@ -14,7 +14,11 @@ import (
 )
 
 func main() {
-	engine := engine.NewHEEngine(config.NewParameters(16, 11, 40, true))
+	engine, err := engine.NewHEEngineFromParameters(config.Sec128Small)
+	if err != nil {
+		fmt.Println("Failed to build engine:", err)
+		return
+	}
 
 	const (
 		DATA_SIZE = 32768 // Slot size
@@ -14,7 +14,11 @@ import (
 )
 
 func main() {
-	engine := engine.NewHEEngine(config.NewParameters(16, 11, 40, true))
+	engine, err := engine.NewHEEngineFromParameters(config.Sec128Small)
+	if err != nil {
+		fmt.Println("Failed to build engine:", err)
+		return
+	}
 
 	// Benchmark settings
 	DATA_SIZE := 1000000 // Datasize
@@ -65,9 +69,9 @@ func main() {
 
 		// [Skewness]
 		fmt.Println("[Skewness]")
-		_, _, skewReal := utils.Skewness(values1)
+		_, _, skewReal := utils.Skewness(values1, utils.MomentOptions{})
 		start = time.Now()
-		skew, _ := engine.Skewness(ctxt1, RANGE)
+		skew, _ := engine.Skewness(ctxt1, RANGE, utils.MomentOptions{})
 		duration = time.Since(start)
 		skewResult, _ := engine.Decrypt(skew)
 		mre = math.Abs(skewResult[0]-skewReal) / math.Abs(skewReal)
@@ -77,9 +81,9 @@ func main() {
 
 		// [Kurtosis]
 		fmt.Println("[Kurtosis]")
-		_, _, kurtReal := utils.Kurtosis(values1)
+		_, _, kurtReal := utils.Kurtosis(values1, utils.MomentOptions{})
 		start = time.Now()
-		kurt, _ := engine.Kurtosis(ctxt1, RANGE)
+		kurt, _ := engine.Kurtosis(ctxt1, RANGE, utils.MomentOptions{})
 		duration = time.Since(start)
 		kurtResult, _ := engine.Decrypt(kurt)
 		mre = math.Abs(kurtResult[0]-kurtReal) / math.Abs(kurtReal)
@@ -89,9 +93,9 @@ func main() {
 
 		// [Coefficient of Variation]
 		fmt.Println("[CoeffVar]")
-		_, _, cvReal := utils.CoeffVar(values1)
+		_, _, cvReal := utils.CoeffVar(values1, utils.MomentOptions{})
 		start = time.Now()
-		cv, _ := engine.CoeffVar(ctxt1, RANGE)
+		cv, _ := engine.CoeffVar(ctxt1, RANGE, utils.MomentOptions{})
 		duration = time.Since(start)
 		cvResult, _ := engine.Decrypt(cv)
 		mre = math.Abs(cvResult[0]-cvReal) / math.Abs(cvReal)
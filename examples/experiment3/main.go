@@ -13,7 +13,13 @@ import (
 )
 
 func main() {
-	engine := engine.NewHEEngine(config.NewParameters(10, 11, 40, true))
+	params := config.Test
+	params.Insecure = true // small and fast, but not secure
+	engine, err := engine.NewHEEngineFromParameters(params)
+	if err != nil {
+		fmt.Println("Failed to build engine:", err)
+		return
+	}
 	EVAL_NUM, B := 10, 50.0
 	ageSlice, _ := utils.ReadCSV("../../examples/dataset/adult_dataset.csv", 0)
 	hpwSlice, _ := utils.ReadCSV("../../examples/dataset/adult_dataset.csv", 12)
@@ -23,17 +29,17 @@ func main() {
 	hpw, _ := engine.Encrypt(hpwSlice)
 	edu, _ := engine.Encrypt(eduSlice)
 
-	_, _, skew_age := utils.Skewness(ageSlice)
-	_, _, kurt_age := utils.Kurtosis(ageSlice)
-	_, _, cfvar_age := utils.CoeffVar(ageSlice)
+	_, _, skew_age := utils.Skewness(ageSlice, utils.MomentOptions{})
+	_, _, kurt_age := utils.Kurtosis(ageSlice, utils.MomentOptions{})
+	_, _, cfvar_age := utils.CoeffVar(ageSlice, utils.MomentOptions{})
 
-	_, _, skew_hpw := utils.Skewness(hpwSlice)
-	_, _, kurt_hpw := utils.Kurtosis(hpwSlice)
-	_, _, cfvar_hpw := utils.CoeffVar(hpwSlice)
+	_, _, skew_hpw := utils.Skewness(hpwSlice, utils.MomentOptions{})
+	_, _, kurt_hpw := utils.Kurtosis(hpwSlice, utils.MomentOptions{})
+	_, _, cfvar_hpw := utils.CoeffVar(hpwSlice, utils.MomentOptions{})
 
-	_, _, skew_edu := utils.Skewness(eduSlice)
-	_, _, kurt_edu := utils.Kurtosis(eduSlice)
-	_, _, cfvar_edu := utils.CoeffVar(eduSlice)
+	_, _, skew_edu := utils.Skewness(eduSlice, utils.MomentOptions{})
+	_, _, kurt_edu := utils.Kurtosis(eduSlice, utils.MomentOptions{})
+	_, _, cfvar_edu := utils.CoeffVar(eduSlice, utils.MomentOptions{})
 
 	fmt.Println("utils.Mean(ageSlice), utils.Mean(hpwSlice), utils.Mean(eduSlice), ", utils.Mean(ageSlice), utils.Mean(hpwSlice), utils.Mean(eduSlice))
 
@@ -70,7 +76,7 @@ func main() {
 		fmt.Println("Age ZNorm", zSNAge[0:1], utils.ZScoreNorm(ageSlice)[:1], AGE_ZNORM_TIME)
 
 		TIME = time.Now()
-		skew1, _ := engine.Skewness(age, B)
+		skew1, _ := engine.Skewness(age, B, utils.MomentOptions{})
 		AGE_SKEW_TIME := time.Since(TIME)
 		skewAge, _ := engine.Decrypt(skew1)
 		AgeSkew_MRE[i] = math.Abs(skewAge[0]-skew_age) / math.Abs(skew_age)
@@ -79,7 +85,7 @@ func main() {
 		fmt.Println("Age skewResult", skewAge[0], math.Abs(skewAge[0]-skew_age), math.Abs(skewAge[0]-skew_age)/math.Abs(skew_age), AGE_SKEW_TIME)
 
 		TIME = time.Now()
-		kurt1, _ := engine.Kurtosis(age, B)
+		kurt1, _ := engine.Kurtosis(age, B, utils.MomentOptions{})
 		AGE_KURT_TIME := time.Since(TIME)
 		kurtAge, _ := engine.Decrypt(kurt1)
 
@@ -89,7 +95,7 @@ func main() {
 		fmt.Println("Age kurtResult", kurtAge[0], math.Abs(kurtAge[0]-kurt_age), math.Abs(kurtAge[0]-kurt_age)/math.Abs(kurt_age), AGE_KURT_TIME)
 
 		TIME = time.Now()
-		ceffvar1, _ := engine.CoeffVar(age, B)
+		ceffvar1, _ := engine.CoeffVar(age, B, utils.MomentOptions{})
 		AGE_CV_TIME := time.Since(TIME)
 		ceffvarAge, _ := engine.Decrypt(ceffvar1)
 		AgeCV_MRE[i] = math.Abs(ceffvarAge[0]-cfvar_age) / math.Abs(cfvar_age)
@@ -106,7 +112,7 @@ func main() {
 		fmt.Println("HPW ZNorm", zSNHpw[0:1], utils.ZScoreNorm(zSNHpw)[:1], HPW_ZNORM_TIME)
 
 		TIME = time.Now()
-		skew2, _ := engine.Skewness(hpw, B)
+		skew2, _ := engine.Skewness(hpw, B, utils.MomentOptions{})
 		HPW_SKEW_TIME := time.Since(TIME)
 		skewHpw, _ := engine.Decrypt(skew2)
 
@@ -116,7 +122,7 @@ func main() {
 		fmt.Println("HPW skewResult", skewHpw[0], math.Abs(skewHpw[0]-skew_hpw), math.Abs(skewHpw[0]-skew_hpw)/math.Abs(skew_hpw), HPW_SKEW_TIME)
 
 		TIME = time.Now()
-		kurt2, _ := engine.Kurtosis(hpw, B)
+		kurt2, _ := engine.Kurtosis(hpw, B, utils.MomentOptions{})
 		HPW_KURT_TIME := time.Since(TIME)
 		kurtHpw, _ := engine.Decrypt(kurt2)
 		HPWKurt_MRE[i] = math.Abs(kurtHpw[0]-kurt_hpw) / math.Abs(kurt_hpw)
@@ -125,7 +131,7 @@ func main() {
 		fmt.Println("HPW kurtResult", kurtHpw[0], math.Abs(kurtHpw[0]-kurt_hpw), math.Abs(kurtHpw[0]-kurt_hpw)/math.Abs(kurt_hpw), HPW_KURT_TIME)
 
 		TIME = time.Now()
-		ceffvar2, _ := engine.CoeffVar(hpw, B)
+		ceffvar2, _ := engine.CoeffVar(hpw, B, utils.MomentOptions{})
 		HPW_CV_TIME := time.Since(TIME)
 		ceffvarAHpw, _ := engine.Decrypt(ceffvar2)
 
@@ -143,7 +149,7 @@ func main() {
 		fmt.Println("Edu ZNorm", zSNEdu[0:1], utils.ZScoreNorm(zSNEdu)[:1], EDU_ZNORM_TIME)
 
 		TIME = time.Now()
-		skew3, _ := engine.Skewness(edu, B)
+		skew3, _ := engine.Skewness(edu, B, utils.MomentOptions{})
 		EDU_SKEW_TIME := time.Since(TIME)
 		skewEdu, _ := engine.Decrypt(skew3)
 
@@ -153,7 +159,7 @@ func main() {
 		fmt.Println("Edu skewResult", skewEdu[0], math.Abs(skewEdu[0]-skew_edu), math.Abs(skewEdu[0]-skew_edu)/math.Abs(skew_edu), EDU_SKEW_TIME)
 
 		TIME = time.Now()
-		kurt3, _ := engine.Kurtosis(edu, B)
+		kurt3, _ := engine.Kurtosis(edu, B, utils.MomentOptions{})
 		EDU_KURT_TIME := time.Since(TIME)
 		kurtEdu, _ := engine.Decrypt(kurt3)
 
@@ -163,7 +169,7 @@ func main() {
 		fmt.Println("Edu kurtResult", kurtEdu[0], math.Abs(kurtEdu[0]-kurt_edu), math.Abs(kurtEdu[0]-kurt_edu)/math.Abs(kurt_edu), EDU_KURT_TIME)
 
 		TIME = time.Now()
-		ceffvar3, _ := engine.CoeffVar(edu, B)
+		ceffvar3, _ := engine.CoeffVar(edu, B, utils.MomentOptions{})
 		EDU_CV_TIME := time.Since(TIME)
 		ceffvarEdu, _ := engine.Decrypt(ceffvar3)
 
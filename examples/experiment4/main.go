@@ -13,7 +13,11 @@ import (
 )
 
 func main() {
-	engine := engine.NewHEEngine(config.NewParameters(16, 11, 40, true))
+	engine, err := engine.NewHEEngineFromParameters(config.Sec128Small)
+	if err != nil {
+		fmt.Println("Failed to build engine:", err)
+		return
+	}
 	ageSlice, _ := utils.ReadCSV("../../examples/dataset/insurance.csv", 0)
 	bmiSlice, _ := utils.ReadCSV("../../examples/dataset/insurance.csv", 2)
 	smokerSlice, _ := utils.ReadCSV("../../examples/dataset/insurance.csv", 4)
@@ -42,9 +46,9 @@ func main() {
 	smoker, _ := engine.Encrypt(smokerSlice)
 	charge, _ := engine.Encrypt(chargeSlice)
 
-	_, _, skew_cg := utils.Skewness(chargeSlice)
-	_, _, kurt_cg := utils.Kurtosis(chargeSlice)
-	_, _, cv_cg := utils.CoeffVar(chargeSlice)
+	_, _, skew_cg := utils.Skewness(chargeSlice, utils.MomentOptions{})
+	_, _, kurt_cg := utils.Kurtosis(chargeSlice, utils.MomentOptions{})
+	_, _, cv_cg := utils.CoeffVar(chargeSlice, utils.MomentOptions{})
 
 	fmt.Println("==============================")
 	TIME := time.Now()
@@ -64,7 +68,7 @@ func main() {
 		fmt.Println("Charge ZNorm", zScoreMaeCharge, zScoreMreCharge, CHARGE_ZNORM_TIME)
 
 		TIME = time.Now()
-		skewCharge, _ := engine.Skewness(charge, B)
+		skewCharge, _ := engine.Skewness(charge, B, utils.MomentOptions{})
 		CHARGE_SKEW_TIME := time.Since(TIME)
 		skCharge, _ := engine.Decrypt(skewCharge)
 		CG_SKEW_MRE[i] = math.Abs(skCharge[0]-skew_cg) / math.Abs(skew_cg)
@@ -73,7 +77,7 @@ func main() {
 		fmt.Println("Charge skewResult", skCharge[0], math.Abs(skCharge[0]-skew_cg), math.Abs(skCharge[0]-skew_cg)/math.Abs(skew_cg), CHARGE_SKEW_TIME)
 
 		TIME = time.Now()
-		kurtCharge, _ := engine.Kurtosis(charge, B)
+		kurtCharge, _ := engine.Kurtosis(charge, B, utils.MomentOptions{})
 		CHARGE_KURT_TIME := time.Since(TIME)
 		ktCharge, _ := engine.Decrypt(kurtCharge)
 		CG_KURT_MRE[i] = math.Abs(ktCharge[0]-kurt_cg) / math.Abs(kurt_cg)
@@ -82,7 +86,7 @@ func main() {
 		fmt.Println("BCharge kurtResult", ktCharge[0], math.Abs(ktCharge[0]-kurt_cg), math.Abs(ktCharge[0]-kurt_cg)/math.Abs(kurt_cg), CHARGE_KURT_TIME)
 
 		TIME = time.Now()
-		cvCharge, _ := engine.CoeffVar(charge, B)
+		cvCharge, _ := engine.CoeffVar(charge, B, utils.MomentOptions{})
 		CHARGE_CV_TIME := time.Since(TIME)
 		cCharge, _ := engine.Decrypt(cvCharge)
 		CG_CV_MRE[i] = math.Abs(cCharge[0]-cv_cg) / math.Abs(cv_cg)
@@ -5,8 +5,6 @@ import (
 	"math"
 
 	"github.com/hm-choi/pp-stat/engine"
-	"github.com/tuneinsight/lattigo/v6/circuits/ckks/minimax"
-	"github.com/tuneinsight/lattigo/v6/core/rlwe"
 )
 
 type TwoLineParams struct {
@@ -70,19 +68,11 @@ func TwoLineApprox(e *engine.HEEngine, ct *engine.HEData, d int) (*engine.HEData
 		return nil, fmt.Errorf("center step input: %w", err)
 	}
 
-	// Step function evaluation using sign poly
-	poly := minimax.NewPolynomial(engine.DefaultPolynomialForSign)
-	eval := minimax.NewEvaluator(e.Params(), e.Evaluator(), e.BTS)
-	stepEval := engine.NewEvaluator(e.Params(), eval, poly)
-
-	stepCtxts := make([]*rlwe.Ciphertext, len(stepInput.Ciphertexts()))
-	for i, c := range stepInput.Ciphertexts() {
-		stepCtxts[i], err = stepEval.Step(c)
-		if err != nil {
-			return nil, fmt.Errorf("step evaluation failed at slot %d: %w", i, err)
-		}
+	// Step function evaluation using the comparison evaluator of the engine
+	beta, err := e.Step(stepInput, 1.0)
+	if err != nil {
+		return nil, fmt.Errorf("step evaluation: %w", err)
 	}
-	beta := engine.NewHEData(stepCtxts, ct.Size(), stepCtxts[0].Level(), ct.Scale())
 
 	// Final interpolation: result = L1*(1-β) + L2*β
 	oneMinusBeta, err := e.Sub(ctOnes, beta)
@@ -0,0 +1,98 @@
+// This example starts a ppstat-server in-process, outsources the statistics of the
+// insurance dataset to it through the client package and checks the decrypted results
+// against the plaintext reference functions of the utils package.
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http/httptest"
+	"os"
+
+	"github.com/hm-choi/pp-stat/client"
+	"github.com/hm-choi/pp-stat/config"
+	"github.com/hm-choi/pp-stat/engine"
+	"github.com/hm-choi/pp-stat/server"
+	"github.com/hm-choi/pp-stat/utils"
+)
+
+func main() {
+	srv := httptest.NewServer(server.NewHandler())
+	defer srv.Close()
+
+	params := config.Test
+	params.Insecure = true // small and fast, but not secure
+	owner, err := engine.NewClientFromParameters(params)
+	if err != nil {
+		fail("client", err)
+	}
+	evk, err := owner.GenEvaluationKeys()
+	if err != nil {
+		fail("generate evaluation keys", err)
+	}
+
+	cl := client.New(srv.URL, nil)
+	if err = cl.Open(owner, evk); err != nil {
+		fail("open session", err)
+	}
+	defer cl.Close()
+
+	ageSlice, _ := utils.ReadCSV("../../examples/dataset/insurance.csv", 0)
+	chargeSlice, _ := utils.ReadCSV("../../examples/dataset/insurance.csv", 6)
+	for i := range chargeSlice {
+		chargeSlice[i] = chargeSlice[i] / 1000.0
+	}
+
+	ageID := upload(owner, cl, ageSlice)
+	chargeID := upload(owner, cl, chargeSlice)
+
+	_, _, skew := utils.Skewness(chargeSlice, utils.MomentOptions{})
+	_, _, kurt := utils.Kurtosis(chargeSlice, utils.MomentOptions{})
+	_, _, cv := utils.CoeffVar(chargeSlice, utils.MomentOptions{})
+	_, pcc, _ := utils.Correlation(ageSlice, chargeSlice)
+
+	B := 100.0
+	check(owner, cl, client.OpMean, "mean(charges)", utils.Mean(chargeSlice), 0, chargeID)
+	check(owner, cl, client.OpVariance, "variance(charges)", utils.Variance(chargeSlice, utils.MomentOptions{}), 0, chargeID)
+	check(owner, cl, client.OpSkewness, "skewness(charges)", skew, B, chargeID)
+	check(owner, cl, client.OpKurtosis, "kurtosis(charges)", kurt, B, chargeID)
+	check(owner, cl, client.OpCoeffVar, "cv(charges)", cv, B, chargeID)
+	check(owner, cl, client.OpPCC, "pcc(age, charges)", pcc, B, ageID, chargeID)
+
+	result, err := cl.Compute(client.OpZScore, B, chargeID)
+	if err != nil {
+		fail("zscore", err)
+	}
+	zscore, _ := owner.Decrypt(result)
+	_, mre := utils.CheckMRE(utils.ZScoreNorm(chargeSlice), zscore, zscore, len(chargeSlice))
+	fmt.Printf("%-20s MRE %e\n", "zscore(charges)", mre)
+}
+
+func upload(owner *engine.Client, cl *client.Client, values []float64) string {
+	ct, err := owner.Encrypt(values)
+	if err != nil {
+		fail("encrypt", err)
+	}
+	id, err := cl.Upload(ct)
+	if err != nil {
+		fail("upload", err)
+	}
+	return id
+}
+
+func check(owner *engine.Client, cl *client.Client, op, name string, expected, B float64, inputs ...string) {
+	result, err := cl.Compute(op, B, inputs...)
+	if err != nil {
+		fail(name, err)
+	}
+	values, err := owner.Decrypt(result)
+	if err != nil {
+		fail(name, err)
+	}
+	fmt.Printf("%-20s HE %f, plain %f, MRE %e\n", name, values[0], expected, math.Abs(values[0]-expected)/math.Abs(expected))
+}
+
+func fail(step string, err error) {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", step, err)
+	os.Exit(1)
+}
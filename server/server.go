@@ -0,0 +1,278 @@
+// Package server exposes an engine.Server over HTTP, so that a data owner can outsource
+// the statistics on encrypted columns to another process without sharing the secret key.
+//
+// The protocol is:
+//
+//	POST   /v1/sessions                   body: Client.WriteEvaluationKeys output   -> {"session": id}
+//	POST   /v1/sessions/{session}/data    body: HEData.WriteTo output                -> {"data": id}
+//	POST   /v1/sessions/{session}/compute body: ComputeRequest as JSON              -> HEData.WriteTo output
+//	DELETE /v1/sessions/{session}
+//
+// Errors are reported with a non-2xx status code and a plain-text message.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/hm-choi/pp-stat/engine"
+)
+
+// ComputeRequest asks for the evaluation of Op on the uploaded data Inputs.
+// Estimator is "population", the default, or "sample", and Raw asks for the kurtosis without subtracting 3,
+// see engine.MomentOptions. They are ignored by the operations other than the moment statistics.
+type ComputeRequest struct {
+	Op        string   `json:"op"`
+	Inputs    []string `json:"inputs"`
+	B         float64  `json:"B"`
+	Estimator string   `json:"estimator,omitempty"`
+	Raw       bool     `json:"raw,omitempty"`
+}
+
+// momentOptions returns the engine.MomentOptions of the request.
+func (req ComputeRequest) momentOptions() (engine.MomentOptions, error) {
+	opts := engine.MomentOptions{Raw: req.Raw}
+	switch req.Estimator {
+	case "", engine.Population.String():
+		opts.Estimator = engine.Population
+	case engine.Sample.String():
+		opts.Estimator = engine.Sample
+	default:
+		return opts, fmt.Errorf("unknown estimator %q", req.Estimator)
+	}
+	return opts, nil
+}
+
+// operation evaluates a statistic on a Server. B is the constant scaling factor of the pp-stat algorithms
+// and opts the options of the moment statistics. The long statistics stop when ctx, the context of the
+// request, is cancelled.
+type operation struct {
+	inputs int
+	eval   func(ctx context.Context, s *engine.Server, in []*engine.HEData, B float64, opts engine.MomentOptions) (*engine.HEData, error)
+}
+
+var operations = map[string]operation{
+	"mean": {1, func(_ context.Context, s *engine.Server, in []*engine.HEData, _ float64, _ engine.MomentOptions) (*engine.HEData, error) {
+		return s.Mean(in[0])
+	}},
+	"variance": {1, func(_ context.Context, s *engine.Server, in []*engine.HEData, _ float64, opts engine.MomentOptions) (*engine.HEData, error) {
+		return s.Variance(in[0], opts)
+	}},
+	"zscore": {1, func(ctx context.Context, s *engine.Server, in []*engine.HEData, B float64, _ engine.MomentOptions) (*engine.HEData, error) {
+		return s.ZScoreNormCtx(ctx, in[0], B)
+	}},
+	"skewness": {1, func(ctx context.Context, s *engine.Server, in []*engine.HEData, B float64, opts engine.MomentOptions) (*engine.HEData, error) {
+		return s.SkewnessCtx(ctx, in[0], B, opts)
+	}},
+	"kurtosis": {1, func(ctx context.Context, s *engine.Server, in []*engine.HEData, B float64, opts engine.MomentOptions) (*engine.HEData, error) {
+		return s.KurtosisCtx(ctx, in[0], B, opts)
+	}},
+	"cv": {1, func(ctx context.Context, s *engine.Server, in []*engine.HEData, B float64, opts engine.MomentOptions) (*engine.HEData, error) {
+		return s.CoeffVarCtx(ctx, in[0], B, opts)
+	}},
+	"pcc": {2, func(ctx context.Context, s *engine.Server, in []*engine.HEData, B float64, _ engine.MomentOptions) (*engine.HEData, error) {
+		return s.PCorrCoeffCtx(ctx, in[0], in[1], B)
+	}},
+}
+
+// session holds the evaluation keys and the data uploaded by one client.
+// The engine.Server evaluator is not safe for concurrent use, so computations of a session are serialized.
+type session struct {
+	mu     sync.Mutex
+	server *engine.Server
+	data   map[string]*engine.HEData
+}
+
+// Default limits of the request bodies of a Handler.
+const (
+	// DefaultMaxKeyBytes bounds the evaluation keys, whose bootstrapping keys take a few gigabytes with LogN=16.
+	DefaultMaxKeyBytes = 8 << 30
+	// DefaultMaxDataBytes bounds an encrypted column, e.g. about 80 ciphertexts of LogN=16 at the top level.
+	DefaultMaxDataBytes = 1 << 30
+	// maxComputeBytes bounds the JSON body of a compute request.
+	maxComputeBytes = 1 << 20
+)
+
+// Handler serves the pp-stat protocol. It keeps every session in memory.
+type Handler struct {
+	// MaxKeyBytes and MaxDataBytes bound the size of the evaluation keys and of an encrypted column
+	// uploaded to the Handler. Larger bodies are rejected with 413 Request Entity Too Large.
+	MaxKeyBytes  int64
+	MaxDataBytes int64
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	mux      *http.ServeMux
+}
+
+// NewHandler returns a Handler without any session, with the default limits of the request bodies.
+func NewHandler() *Handler {
+	h := &Handler{
+		MaxKeyBytes:  DefaultMaxKeyBytes,
+		MaxDataBytes: DefaultMaxDataBytes,
+		sessions:     map[string]*session{},
+		mux:          http.NewServeMux(),
+	}
+	h.mux.HandleFunc("POST /v1/sessions", h.createSession)
+	h.mux.HandleFunc("DELETE /v1/sessions/{session}", h.deleteSession)
+	h.mux.HandleFunc("POST /v1/sessions/{session}/data", h.uploadData)
+	h.mux.HandleFunc("POST /v1/sessions/{session}/compute", h.compute)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) createSession(w http.ResponseWriter, r *http.Request) {
+	server, err := engine.ReadServer(http.MaxBytesReader(w, r.Body, h.MaxKeyBytes))
+	if err != nil {
+		readError(w, "read evaluation keys", err)
+		return
+	}
+
+	id, err := newID()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.mu.Lock()
+	h.sessions[id] = &session{server: server, data: map[string]*engine.HEData{}}
+	h.mu.Unlock()
+
+	writeJSON(w, map[string]string{"session": id})
+}
+
+func (h *Handler) deleteSession(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("session")
+	h.mu.Lock()
+	_, ok := h.sessions[id]
+	delete(h.sessions, id)
+	h.mu.Unlock()
+
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown session %q", id), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) uploadData(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+
+	data := new(engine.HEData)
+	if _, err := data.ReadFrom(http.MaxBytesReader(w, r.Body, h.MaxDataBytes)); err != nil {
+		readError(w, "read data", err)
+		return
+	}
+	if data.Fingerprint() != s.server.Fingerprint() {
+		http.Error(w, fmt.Sprintf("data belongs to parameters %s, session uses %s", data.Fingerprint(), s.server.Fingerprint()), http.StatusBadRequest)
+		return
+	}
+
+	id, err := newID()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.mu.Lock()
+	s.data[id] = data
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]string{"data": id})
+}
+
+func (h *Handler) compute(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+
+	var req ComputeRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxComputeBytes)).Decode(&req); err != nil {
+		readError(w, "decode request", err)
+		return
+	}
+	op, ok := operations[req.Op]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown operation %q", req.Op), http.StatusBadRequest)
+		return
+	}
+	if len(req.Inputs) != op.inputs {
+		http.Error(w, fmt.Sprintf("operation %q takes %d inputs, got %d", req.Op, op.inputs, len(req.Inputs)), http.StatusBadRequest)
+		return
+	}
+	opts, err := req.momentOptions()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inputs := make([]*engine.HEData, len(req.Inputs))
+	for i, id := range req.Inputs {
+		if inputs[i], ok = s.data[id]; !ok {
+			http.Error(w, fmt.Sprintf("unknown data %q", id), http.StatusNotFound)
+			return
+		}
+	}
+
+	result, err := op.eval(r.Context(), s.server, inputs, req.B, opts)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("%s: %v", req.Op, err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if _, err = result.WriteTo(w); err != nil {
+		// The status line is already sent; the client detects the truncated body when decoding.
+		return
+	}
+}
+
+func (h *Handler) session(w http.ResponseWriter, r *http.Request) (*session, bool) {
+	id := r.PathValue("session")
+	h.mu.Lock()
+	s, ok := h.sessions[id]
+	h.mu.Unlock()
+
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown session %q", id), http.StatusNotFound)
+	}
+	return s, ok
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// readError reports an error while reading a request body, with 413 if the body exceeds its limit.
+func readError(w http.ResponseWriter, what string, err error) {
+	status := http.StatusBadRequest
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		status = http.StatusRequestEntityTooLarge
+	}
+	http.Error(w, fmt.Sprintf("%s: %v", what, err), status)
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", fmt.Errorf("generate identifier: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,150 @@
+package server_test
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hm-choi/pp-stat/client"
+	"github.com/hm-choi/pp-stat/config"
+	"github.com/hm-choi/pp-stat/engine"
+	"github.com/hm-choi/pp-stat/server"
+	"github.com/hm-choi/pp-stat/utils"
+)
+
+const dataset = "../examples/dataset/insurance.csv"
+
+// newOwner returns a client with the small insecure Test parameters and its evaluation keys.
+func newOwner(t *testing.T) (*engine.Client, *engine.EvaluationKeys) {
+	t.Helper()
+	params := config.Test
+	params.Insecure = true
+	owner, err := engine.NewClientFromParameters(params)
+	if err != nil {
+		t.Fatalf("NewClientFromParameters: %v", err)
+	}
+	evk, err := owner.GenEvaluationKeys()
+	if err != nil {
+		t.Fatalf("GenEvaluationKeys: %v", err)
+	}
+	return owner, evk
+}
+
+// openSession starts h in-process and opens a session with the keys of a new owner.
+func openSession(t *testing.T, h http.Handler) (*engine.Client, *client.Client) {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	owner, evk := newOwner(t)
+	cl := client.New(srv.URL, nil)
+	if err := cl.Open(owner, evk); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { cl.Close() })
+	return owner, cl
+}
+
+func upload(t *testing.T, owner *engine.Client, cl *client.Client, values []float64) string {
+	t.Helper()
+	ct, err := owner.Encrypt(values)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	id, err := cl.Upload(ct)
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	return id
+}
+
+func TestCompute(t *testing.T) {
+	owner, cl := openSession(t, server.NewHandler())
+
+	age, err := utils.ReadCSV(dataset, 0)
+	if err != nil {
+		t.Fatalf("ReadCSV: %v", err)
+	}
+	charges, err := utils.ReadCSV(dataset, 6)
+	if err != nil {
+		t.Fatalf("ReadCSV: %v", err)
+	}
+	for i := range charges {
+		charges[i] /= 1000
+	}
+	ageID := upload(t, owner, cl, age)
+	chargesID := upload(t, owner, cl, charges)
+	_, pcc, err := utils.Correlation(age, charges)
+	if err != nil {
+		t.Fatalf("Correlation: %v", err)
+	}
+	sample := utils.MomentOptions{Estimator: utils.Sample}
+	_, _, skew := utils.Skewness(charges, utils.MomentOptions{})
+	_, _, kurt := utils.Kurtosis(charges, utils.MomentOptions{})
+	_, _, sampleKurt := utils.Kurtosis(charges, sample)
+	_, _, cv := utils.CoeffVar(charges, utils.MomentOptions{})
+
+	// want holds the expected first values of the result, which has a single value replicated in
+	// every slot except for zscore.
+	for _, tc := range []struct {
+		op     string
+		opts   engine.MomentOptions
+		inputs []string
+		want   []float64
+	}{
+		{client.OpMean, engine.MomentOptions{}, []string{chargesID}, []float64{utils.Mean(charges)}},
+		{client.OpVariance, engine.MomentOptions{}, []string{chargesID}, []float64{utils.Variance(charges, utils.MomentOptions{})}},
+		{client.OpVariance, sample, []string{chargesID}, []float64{utils.Variance(charges, sample)}},
+		{client.OpZScore, engine.MomentOptions{}, []string{chargesID}, utils.ZScoreNorm(charges)},
+		{client.OpSkewness, engine.MomentOptions{}, []string{chargesID}, []float64{skew}},
+		{client.OpKurtosis, engine.MomentOptions{}, []string{chargesID}, []float64{kurt}},
+		{client.OpKurtosis, sample, []string{chargesID}, []float64{sampleKurt}},
+		{client.OpCoeffVar, engine.MomentOptions{}, []string{chargesID}, []float64{cv}},
+		{client.OpPCC, engine.MomentOptions{}, []string{ageID, chargesID}, []float64{pcc}},
+	} {
+		result, err := cl.ComputeWithOptions(tc.op, 100, tc.opts, tc.inputs...)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.op, err)
+		}
+		values, err := owner.Decrypt(result)
+		if err != nil {
+			t.Fatalf("%s: Decrypt: %v", tc.op, err)
+		}
+		for i, want := range tc.want {
+			if rel := math.Abs(values[i]-want) / math.Max(math.Abs(want), 1); rel > 1e-4 {
+				t.Errorf("%s %+v: value %d: got %v, want %v (relative error %.2e)", tc.op, tc.opts, i, values[i], want, rel)
+				break
+			}
+		}
+	}
+}
+
+func TestUploadTooLarge(t *testing.T) {
+	h := server.NewHandler()
+	owner, cl := openSession(t, h)
+	h.MaxDataBytes = 1 << 10
+
+	ct, err := owner.Encrypt([]float64{1, 2, 3})
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	_, err = cl.Upload(ct)
+	if err == nil || !strings.Contains(err.Error(), "413") {
+		t.Fatalf("Upload of a column larger than MaxDataBytes: got %v, want 413", err)
+	}
+}
+
+func TestOpenTooLarge(t *testing.T) {
+	h := server.NewHandler()
+	h.MaxKeyBytes = 1 << 10
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	owner, evk := newOwner(t)
+	err := client.New(srv.URL, nil).Open(owner, evk)
+	if err == nil || !strings.Contains(err.Error(), "413") {
+		t.Fatalf("Open with keys larger than MaxKeyBytes: got %v, want 413", err)
+	}
+}
@@ -26,19 +26,84 @@ func Mean(data []float64) float64 {
 	return sum / float64(len(data))
 }
 
+// Estimator selects the estimator of the variance, skewness and kurtosis.
+type Estimator int
+
+const (
+	// Population divides the central moments by N.
+	Population Estimator = iota
+	// Sample is the unbiased variance, which divides by N-1, with the adjusted Fisher–Pearson
+	// skewness G1 and excess kurtosis G2 of the sample.
+	Sample
+)
+
+// String returns "population" or "sample", the names of the estimators in the requests of a ppstat-server.
+func (e Estimator) String() string {
+	switch e {
+	case Population:
+		return "population"
+	case Sample:
+		return "sample"
+	}
+	return fmt.Sprintf("Estimator(%d)", int(e))
+}
+
+// MomentOptions are the options of Variance, Skewness, Kurtosis and CoeffVar. The zero value is
+// the population estimator, with the excess kurtosis m4/m2² - 3.
+type MomentOptions struct {
+	Estimator Estimator
+	// Raw makes Kurtosis return the kurtosis without subtracting 3, that of the normal distribution.
+	// It is ignored by the other statistics.
+	Raw bool
+}
+
+// VarianceScale returns the factor N/(N-1) of the unbiased variance of n values for the Sample
+// estimator, and 1 for the Population one.
+func (o MomentOptions) VarianceScale(n float64) float64 {
+	if o.Estimator == Sample {
+		return n / (n - 1)
+	}
+	return 1
+}
+
+// SkewnessScale returns the factor sqrt(N(N-1))/(N-2) of the adjusted Fisher–Pearson skewness
+// G1 = factor·g1 of n values for the Sample estimator, and 1 for the Population one.
+func (o MomentOptions) SkewnessScale(n float64) float64 {
+	if o.Estimator == Sample {
+		return math.Sqrt(n*(n-1)) / (n - 2)
+	}
+	return 1
+}
+
+// KurtosisAffine returns the factor and the term turning the kurtosis m4/m2² of n values into the
+// one of the options: G2 = (N-1)/((N-2)(N-3))·((N+1)·(m4/m2² - 3) + 6) for the Sample estimator,
+// plus 3 if Raw is set.
+func (o MomentOptions) KurtosisAffine(n float64) (scale float64, shift float64) {
+	scale, shift = 1, -3
+	if o.Estimator == Sample {
+		c := (n - 1) / ((n - 2) * (n - 3))
+		scale, shift = c*(n+1), c*(6-3*(n+1))
+	}
+	if o.Raw {
+		shift += 3
+	}
+	return scale, shift
+}
+
 // Variance returns the variance of the input slice.
-func Variance(data []float64) float64 {
+func Variance(data []float64, opts MomentOptions) float64 {
 	m := Mean(data)
 	sum := 0.0
 	for _, v := range data {
 		sum += (v - m) * (v - m)
 	}
-	return sum / float64(len(data))
+	n := float64(len(data))
+	return sum / n * opts.VarianceScale(n)
 }
 
 // StdDev returns the standard deviation of the input slice.
 func StdDev(data []float64) float64 {
-	return math.Sqrt(Variance(data))
+	return math.Sqrt(Variance(data, MomentOptions{}))
 }
 
 func ZScoreNorm(data []float64) []float64 {
@@ -94,8 +159,8 @@ func Correlation(x, y []float64) (float64, float64, error) {
 	return cov, cov / (stdX * stdY), nil
 }
 
-// Kurtosis returns the kurtosis of input slice x.
-func Kurtosis(data []float64) (float64, float64, float64) {
+// Kurtosis returns the mean, the standard deviation and the kurtosis of input slice x.
+func Kurtosis(data []float64, opts MomentOptions) (float64, float64, float64) {
 	n := float64(len(data))
 	if n < 4 {
 		panic("hi") // 첨도 계산을 위해 최소 4개 이상의 데이터 필요
@@ -109,12 +174,13 @@ func Kurtosis(data []float64) (float64, float64, float64) {
 		sum += math.Pow((v-mean)/stdDev, 4)
 	}
 
-	kurtosis := (sum / n) - 3 // Excess Kurtosis (정규분포의 첨도 3을 기준으로)
-	return mean, stdDev, kurtosis
+	scale, shift := opts.KurtosisAffine(n)
+	kurtosis := (sum/n)*scale + shift
+	return mean, math.Sqrt(Variance(data, opts)), kurtosis
 }
 
-// Kurtosis returns the kurtosis of input slice x.
-func Skewness(data []float64) (float64, float64, float64) {
+// Skewness returns the mean, the standard deviation and the skewness of input slice x.
+func Skewness(data []float64, opts MomentOptions) (float64, float64, float64) {
 	n := float64(len(data))
 	if n < 4 {
 		panic("hi") // 첨도 계산을 위해 최소 4개 이상의 데이터 필요
@@ -128,13 +194,13 @@ func Skewness(data []float64) (float64, float64, float64) {
 		sum += math.Pow((v-mean)/stdDev, 3)
 	}
 
-	kurtosis := (sum / n) //- 3 // Excess Kurtosis (정규분포의 첨도 3을 기준으로)
-	return mean, stdDev, kurtosis
+	skewness := (sum / n) * opts.SkewnessScale(n)
+	return mean, math.Sqrt(Variance(data, opts)), skewness
 }
 
 // CoeffVar calculates the coefficient of variation of a slice of float64 numbers.
 // Returns an error if the slice is empty or the mean is zero.
-func CoeffVar(data []float64) (mean float64, stdDev float64, coeffVar float64) {
+func CoeffVar(data []float64, opts MomentOptions) (mean float64, stdDev float64, coeffVar float64) {
 	if len(data) == 0 {
 		return 0, 0, 0
 	}
@@ -156,9 +222,144 @@ func CoeffVar(data []float64) (mean float64, stdDev float64, coeffVar float64) {
 		diff := value - mean
 		varianceSum += diff * diff
 	}
-	stdDev = math.Sqrt(varianceSum / float64(len(data)))
+	stdDev = math.Sqrt(varianceSum / float64(len(data)) * opts.VarianceScale(float64(len(data))))
 
 	// Calculate coefficient of variation
 	coeffVar = stdDev / mean
 	return mean, stdDev, coeffVar
 }
+
+// LinearRegression fits y ≈ intercept + Σ coefficients[j]·x[j] by ordinary least squares, solving the
+// normal equations on the covariances of the features by Gaussian elimination, and returns the
+// coefficient of determination r2 of the fit.
+func LinearRegression(x [][]float64, y []float64) (coefficients []float64, intercept float64, r2 float64, err error) {
+	k := len(x)
+	if k == 0 {
+		return nil, 0, 0, fmt.Errorf("no features")
+	}
+
+	// Augmented matrix [cov(x) | cov(x, y)]
+	a := make([][]float64, k)
+	for i := range k {
+		a[i] = make([]float64, k+1)
+		for j := range k {
+			if a[i][j], err = Covariance(x[i], x[j]); err != nil {
+				return nil, 0, 0, err
+			}
+		}
+		if a[i][k], err = Covariance(x[i], y); err != nil {
+			return nil, 0, 0, err
+		}
+	}
+
+	for c := range k {
+		p := c
+		for i := c + 1; i < k; i++ {
+			if math.Abs(a[i][c]) > math.Abs(a[p][c]) {
+				p = i
+			}
+		}
+		if a[p][c] == 0 {
+			return nil, 0, 0, fmt.Errorf("the features are collinear")
+		}
+		a[c], a[p] = a[p], a[c]
+		for i := range k {
+			if i != c {
+				f := a[i][c] / a[c][c]
+				for j := c; j <= k; j++ {
+					a[i][j] -= f * a[c][j]
+				}
+			}
+		}
+	}
+
+	coefficients = make([]float64, k)
+	intercept = Mean(y)
+	explained := 0.0
+	for j := range k {
+		coefficients[j] = a[j][k] / a[j][j]
+		intercept -= coefficients[j] * Mean(x[j])
+		cov, _ := Covariance(x[j], y)
+		explained += coefficients[j] * cov
+	}
+	return coefficients, intercept, explained / Variance(y, MomentOptions{}), nil
+}
+
+// Rank returns the rank of every value of data, from 1 for the smallest to len(data) for the largest.
+// Tied values get the average of their ranks.
+func Rank(data []float64) []float64 {
+	ranks := make([]float64, len(data))
+	for i, x := range data {
+		ranks[i] = 1
+		for j, y := range data {
+			if j != i && y < x {
+				ranks[i]++
+			} else if j != i && y == x {
+				ranks[i] += 0.5
+			}
+		}
+	}
+	return ranks
+}
+
+// SpearmanCorr returns the Spearman rank correlation coefficient of x and y, the Pearson correlation
+// coefficient of their ranks.
+func SpearmanCorr(x, y []float64) (float64, error) {
+	_, corr, err := Correlation(Rank(x), Rank(y))
+	return corr, err
+}
+
+// TTest returns the t statistic comparing the means of x and y and its degrees of freedom, for Student's
+// test with the pooled variance, or for Welch's test with the Welch–Satterthwaite degrees of freedom.
+func TTest(x, y []float64, welch bool) (t float64, df float64) {
+	n1, n2 := float64(len(x)), float64(len(y))
+	s1 := Variance(x, MomentOptions{Estimator: Sample})
+	s2 := Variance(y, MomentOptions{Estimator: Sample})
+	if !welch {
+		pooled := ((n1-1)*s1 + (n2-1)*s2) / (n1 + n2 - 2)
+		return (Mean(x) - Mean(y)) / math.Sqrt(pooled*(1/n1+1/n2)), n1 + n2 - 2
+	}
+	a, b := s1/n1, s2/n2
+	return (Mean(x) - Mean(y)) / math.Sqrt(a+b), (a + b) * (a + b) / (a*a/(n1-1) + b*b/(n2-1))
+}
+
+// ChiSquare returns the contingency table of two categorical columns, given as the indicator columns of
+// their categories like those of OneHot, and Pearson's χ² statistic of the test of their independence.
+func ChiSquare(a, b [][]float64) (observed [][]float64, statistic float64) {
+	n := float64(len(a[0]))
+	observed = make([][]float64, len(a))
+	for i := range a {
+		observed[i] = make([]float64, len(b))
+		for j := range b {
+			for r := range a[i] {
+				observed[i][j] += a[i][r] * b[j][r]
+			}
+		}
+	}
+	for i := range a {
+		for j := range b {
+			expected := Mean(a[i]) * Mean(b[j]) * n
+			statistic += (observed[i][j] - expected) * (observed[i][j] - expected) / expected
+		}
+	}
+	return observed, statistic
+}
+
+// ANOVA returns the between-group and within-group sums of squares of the one-way analysis of variance of
+// data over the groups, given as indicator columns like those of OneHot, and their F ratio.
+func ANOVA(data []float64, groups [][]float64) (between float64, within float64, f float64) {
+	mean := Mean(data)
+	for _, group := range groups {
+		var n, sum float64
+		for i, g := range group {
+			n += g
+			sum += g * data[i]
+		}
+		between += n * (sum/n - mean) * (sum/n - mean)
+		for i, g := range group {
+			within += g * (data[i] - sum/n) * (data[i] - sum/n)
+		}
+	}
+	n, k := float64(len(data)), float64(len(groups))
+	return between, within, (between / (k - 1)) / (within / (n - k))
+}
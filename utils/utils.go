@@ -7,6 +7,7 @@ import (
 	"math"
 	"math/rand/v2"
 	"os"
+	"sort"
 	"strconv"
 )
 
@@ -67,6 +68,8 @@ func IsPowerOfTwo(x float64) bool {
 	return frac == 0
 }
 
+// ReadCSV reads the column index of a CSV file with a header row as numbers, with yes and no read as 1 and 0.
+// The cells holding anything else are skipped.
 func ReadCSV(fileName string, index int) ([]float64, error) {
 	file, err := os.Open(fileName)
 	if err != nil {
@@ -82,6 +85,9 @@ func ReadCSV(fileName string, index int) ([]float64, error) {
 	data := []float64{}
 	for i, v := range rows {
 		if i != 0 {
+			if index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("row %d: column %d out of range, the row has %d columns", i, index, len(v))
+			}
 			f, err := strconv.ParseFloat(v[index], 64)
 			if err == nil {
 				data = append(data, f)
@@ -96,3 +102,56 @@ func ReadCSV(fileName string, index int) ([]float64, error) {
 	}
 	return data, nil
 }
+
+// ReadCategoricalCSV reads the column index of a CSV file with a header row, like ReadCSV, and
+// one-hot encodes its values with OneHot.
+func ReadCategoricalCSV(fileName string, index int) ([]string, [][]float64, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("file open failed: %w", err)
+	}
+	defer file.Close()
+	rdr := csv.NewReader(bufio.NewReader(file))
+
+	rows, err := rdr.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("file read failed: %w", err)
+	}
+
+	values := []string{}
+	for i, v := range rows {
+		if i != 0 {
+			if index < 0 || index >= len(v) {
+				return nil, nil, fmt.Errorf("row %d: column %d out of range, the row has %d columns", i, index, len(v))
+			}
+			values = append(values, v[index])
+		}
+	}
+	categories, columns := OneHot(values)
+	return categories, columns, nil
+}
+
+// OneHot returns the sorted distinct values of a categorical column and, for each of them,
+// the indicator column holding 1 on the rows with that value and 0 on the others.
+func OneHot(values []string) ([]string, [][]float64) {
+	seen := map[string]bool{}
+	categories := []string{}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			categories = append(categories, v)
+		}
+	}
+	sort.Strings(categories)
+
+	columns := make([][]float64, len(categories))
+	for k, category := range categories {
+		columns[k] = make([]float64, len(values))
+		for i, v := range values {
+			if v == category {
+				columns[k][i] = 1.0
+			}
+		}
+	}
+	return categories, columns
+}